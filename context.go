@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ecletus/roles"
@@ -110,7 +111,12 @@ type Context struct {
 	RedirectTo    string
 	MetaTreeStack *NameStacker
 
+	// ValidatorTimeout is the default deadline of each async validator
+	ValidatorTimeout time.Duration
+
 	DecoderExcludes *DecoderExcludes
+	recordSnapshots *RecordSnapshots
+	memo            *Memo
 	requestTime     time.Time
 
 	MetaContextFactory func(parent *Context, res interface{}, record interface{}) *Context
@@ -178,6 +184,19 @@ func (this *Context) Err() error {
 	return this.Errors
 }
 
+// CheckErr returns the error of std context or the context Errors. Unlike Err, returns nil if there are no errors.
+func (this *Context) CheckErr() error {
+	if this.context != nil {
+		if err := this.context.Err(); err != nil {
+			return err
+		}
+	}
+	if this.Errors.HasError() {
+		return this.Errors
+	}
+	return nil
+}
+
 func (this *Context) Anonymous() bool {
 	return this.CurrentUser() == nil
 }
@@ -190,6 +209,17 @@ func (this Context) WithDB(f func(ctx *Context), db ...*aorm.DB) *Context {
 	return &this
 }
 
+// StdContext returns the standard context of this context or of request
+func (this *Context) StdContext() context.Context {
+	if this.context != nil {
+		return this.context
+	}
+	if this.Request != nil {
+		return this.Request.Context()
+	}
+	return context.Background()
+}
+
 func (this Context) WithContext(ctx context.Context) *Context {
 	this.context = ctx
 	return &this
@@ -722,3 +752,82 @@ func (this *DecoderExcludes) HasItemOf(path string) bool {
 	}
 	return false
 }
+
+// RecordSnapshots holds copies of records loaded before decoding, keyed by record pointer. It is used to detect
+// the changed fields on save.
+type RecordSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[interface{}]interface{}
+}
+
+func (this *RecordSnapshots) Set(record, snapshot interface{}) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.snapshots == nil {
+		this.snapshots = map[interface{}]interface{}{}
+	}
+	this.snapshots[record] = snapshot
+}
+
+func (this *RecordSnapshots) Get(record interface{}) (snapshot interface{}, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	snapshot, ok = this.snapshots[record]
+	return
+}
+
+// Pop returns and removes the snapshot of record
+func (this *RecordSnapshots) Pop(record interface{}) (snapshot interface{}, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if snapshot, ok = this.snapshots[record]; ok {
+		delete(this.snapshots, record)
+	}
+	return
+}
+
+// RecordSnapshots returns the record snapshots of root context
+func (this *Context) RecordSnapshots() *RecordSnapshots {
+	root := this.Root()
+	if root.recordSnapshots == nil {
+		root.recordSnapshots = &RecordSnapshots{}
+	}
+	return root.recordSnapshots
+}
+
+// Memo is the request scoped memoization store
+type Memo struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+func (this *Memo) Get(key string) (value interface{}, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	value, ok = this.values[key]
+	return
+}
+
+func (this *Memo) Set(key string, value interface{}) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.values == nil {
+		this.values = map[string]interface{}{}
+	}
+	this.values[key] = value
+}
+
+func (this *Memo) Delete(key string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	delete(this.values, key)
+}
+
+// Memo returns the memoization store of root context
+func (this *Context) Memo() *Memo {
+	root := this.Root()
+	if root.memo == nil {
+		root.memo = &Memo{}
+	}
+	return root.memo
+}
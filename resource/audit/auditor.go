@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+
+	"github.com/ecletus/core/resource"
+)
+
+// Auditor writes audit entries of resources writes into the site system DB
+type Auditor struct {
+	// Retention is the max age of entries. Zero value keeps entries forever.
+	Retention time.Duration
+	// IgnoreFields are the field names excluded from changes
+	IgnoreFields map[string]bool
+	// Strict if true, the audit errors are returned to CRUD, otherwise are logged
+	Strict bool
+
+	olds sync.Map
+}
+
+func New() *Auditor {
+	return &Auditor{}
+}
+
+// Migrate creates or updates the entries table
+func (this *Auditor) Migrate(db *aorm.DB) error {
+	return db.AutoMigrate(&Entry{}).Error
+}
+
+// Register subscribes the after create, update and delete DB events of resource
+func (this *Auditor) Register(res resource.Resourcer) (err error) {
+	if err = resource.OnDBActionE(res, this.captureOld,
+		resource.E_DB_ACTION_UPDATE.Before(),
+		resource.E_DB_ACTION_UPDATE_MANY.Before(),
+	); err != nil {
+		return
+	}
+
+	if err = resource.OnDBAction(res, func(e *resource.DBEvent) {
+		for _, record := range this.eventRecords(e) {
+			this.olds.Delete(record)
+		}
+	}, resource.E_DB_ACTION_UPDATE.Error(), resource.E_DB_ACTION_UPDATE_MANY.Error()); err != nil {
+		return
+	}
+
+	return resource.OnDBActionE(res, this.audit,
+		resource.E_DB_ACTION_CREATE.After(),
+		resource.E_DB_ACTION_UPDATE.After(),
+		resource.E_DB_ACTION_DELETE.After(),
+		resource.E_DB_ACTION_RESTORE.After(),
+		resource.E_DB_ACTION_PURGE.After(),
+		resource.E_DB_ACTION_CREATE_MANY.After(),
+		resource.E_DB_ACTION_UPDATE_MANY.After(),
+		resource.E_DB_ACTION_DELETE_MANY.After(),
+	)
+}
+
+func (this *Auditor) eventRecords(e *resource.DBEvent) []interface{} {
+	if records := e.Records(); records != nil {
+		return records
+	}
+	return []interface{}{e.Result()}
+}
+
+// captureOld loads the stored records before update if the old record isn't provided
+func (this *Auditor) captureOld(e *resource.DBEvent) (err error) {
+	if e.Old() != nil {
+		this.olds.Store(e.Result(), e.Old())
+		return
+	}
+	res := e.Resource()
+	for _, record := range this.eventRecords(e) {
+		old := res.NewStruct(e.Context.Site)
+		if err = e.DB().New().Where(res.GetKey(record)).First(old).Error; err != nil {
+			if aorm.IsRecordNotFoundError(err) {
+				err = nil
+				continue
+			}
+			return this.error(err)
+		}
+		this.olds.Store(record, old)
+	}
+	return
+}
+
+// audit writes the entries of event records. Inside a CRUD transaction, the entries are written after the commit,
+// so rolled back writes (including failed savepoints) are not audited. Inside a transaction began by the
+// application, the entries are written through the transaction, so its DB must have the entries table.
+func (this *Auditor) audit(e *resource.DBEvent) (err error) {
+	var (
+		res     = e.Resource()
+		action  = Action(e.Action)
+		entries []*Entry
+	)
+
+	for _, record := range this.eventRecords(e) {
+		var old, new interface{}
+
+		switch action {
+		case ActionCreate, ActionRestore:
+			new = record
+		case ActionUpdate:
+			new = record
+			old, _ = this.olds.Load(record)
+			this.olds.Delete(record)
+		default:
+			old = record
+		}
+
+		entry := this.NewEntry(e.Context, res, action, record)
+		if err = entry.SetChanges(Diff(res.GetModelStruct(), old, new, this.IgnoreFields)); err != nil {
+			return this.error(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if resource.AfterCommit(e.DB(), func(db *aorm.DB) {
+		if err := this.write(this.db(e.Context, db), entries); err != nil {
+			log.Errorf("audit failed: %v", err)
+		}
+	}) {
+		return
+	}
+	if resource.IsTransaction(e.DB()) {
+		return this.error(this.write(e.DB().New(), entries))
+	}
+	return this.error(this.write(this.db(e.Context, e.DB()), entries))
+}
+
+func (this *Auditor) write(db *aorm.DB, entries []*Entry) (err error) {
+	for _, entry := range entries {
+		if err = db.Create(entry).Error; err != nil {
+			return
+		}
+	}
+	return
+}
+
+// NewEntry creates a new entry with the context request metadata
+func (this *Auditor) NewEntry(ctx *core.Context, res resource.Resourcer, action string, record interface{}) *Entry {
+	entry := &Entry{
+		Action:      action,
+		ResourceUID: res.GetResource().UID,
+	}
+	if id := res.GetKey(record); id != nil {
+		entry.RecordID = id.String()
+	}
+	if ctx.CurrentUser() != nil {
+		if id := ctx.UserID(); id != nil {
+			entry.UserID = id.String()
+		}
+	}
+	if r := ctx.Request; r != nil {
+		entry.Method = r.Method
+		entry.URI = r.RequestURI
+		entry.RemoteAddr = r.RemoteAddr
+		entry.UserAgent = r.UserAgent()
+	}
+	return entry
+}
+
+// db returns the site system DB, or db
+func (this *Auditor) db(ctx *core.Context, db *aorm.DB) *aorm.DB {
+	if ctx.Site != nil {
+		if system := ctx.Site.GetSystemDB(); system != nil {
+			if d, _ := db.Get(core.PREFIX + ".db"); d != system {
+				db = system.DB
+			}
+		}
+	}
+	return db.New()
+}
+
+func (this *Auditor) error(err error) error {
+	if err == nil || this.Strict {
+		return err
+	}
+	log.Errorf("audit failed: %v", err)
+	return nil
+}
+
+// Action returns the audit action name of DB action
+func Action(action resource.DBActionEvent) string {
+	switch {
+	case (action & (resource.E_DB_ACTION_CREATE | resource.E_DB_ACTION_CREATE_MANY)) != 0:
+		return ActionCreate
+	case (action & (resource.E_DB_ACTION_UPDATE | resource.E_DB_ACTION_UPDATE_MANY)) != 0:
+		return ActionUpdate
+	case (action & resource.E_DB_ACTION_RESTORE) != 0:
+		return ActionRestore
+	case (action & resource.E_DB_ACTION_PURGE) != 0:
+		return ActionPurge
+	default:
+		return ActionDelete
+	}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"reflect"
+
+	"github.com/go-aorm/aorm"
+)
+
+// Diff returns the changes of normal fields between old and new records. If old (or new) is nil, all non
+// blank fields of new (or old) are returned.
+func Diff(modelStruct *aorm.ModelStruct, old, new interface{}, ignore map[string]bool) (changes []Change) {
+	var oldValue, newValue reflect.Value
+	if old != nil {
+		oldValue = reflect.Indirect(reflect.ValueOf(old))
+	}
+	if new != nil {
+		newValue = reflect.Indirect(reflect.ValueOf(new))
+	}
+
+	for _, field := range modelStruct.StructFields {
+		if field.IsIgnored || !field.IsNormal || ignore[field.Name] {
+			continue
+		}
+		var (
+			change = Change{Field: field.Name}
+			ov, nv reflect.Value
+		)
+		if oldValue.IsValid() {
+			ov = oldValue.FieldByIndex(field.StructIndex)
+			if !aorm.IsBlank(ov) {
+				change.Old = ov.Interface()
+			}
+		}
+		if newValue.IsValid() {
+			nv = newValue.FieldByIndex(field.StructIndex)
+			if !aorm.IsBlank(nv) {
+				change.New = nv.Interface()
+			}
+		}
+		if change.Old == nil && change.New == nil {
+			continue
+		}
+		if ov.IsValid() && nv.IsValid() && reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+)
+
+// Change is the change of a record field
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// Entry is an audit log entry
+type Entry struct {
+	ID          uint64    `aorm:"primary_key"`
+	CreatedAt   time.Time `aorm:"index"`
+	Action      string    `aorm:"size:16"`
+	ResourceUID string    `aorm:"size:255;index"`
+	RecordID    string    `aorm:"size:255;index"`
+	UserID      string    `aorm:"size:255;index"`
+	Method      string    `aorm:"size:16"`
+	URI         string    `aorm:"type:text"`
+	RemoteAddr  string    `aorm:"size:255"`
+	UserAgent   string    `aorm:"type:text"`
+	Changes     string    `aorm:"type:text"`
+}
+
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+// GetChanges returns the decoded field changes
+func (this *Entry) GetChanges() (changes []Change, err error) {
+	if this.Changes != "" {
+		err = json.Unmarshal([]byte(this.Changes), &changes)
+	}
+	return
+}
+
+// SetChanges encodes the field changes
+func (this *Entry) SetChanges(changes []Change) (err error) {
+	var b []byte
+	if b, err = json.Marshal(changes); err == nil {
+		this.Changes = string(b)
+	}
+	return
+}
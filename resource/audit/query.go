@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/go-aorm/aorm"
+)
+
+// Query is the entries filter. Zero value fields are ignored.
+type Query struct {
+	ResourceUID string
+	RecordID    string
+	UserID      string
+	Actions     []string
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+	Offset      int
+}
+
+// Find finds entries matching query ordered by newest first
+func (this *Auditor) Find(db *aorm.DB, query *Query) (entries []*Entry, err error) {
+	db = db.New().Model(&Entry{})
+	if query.ResourceUID != "" {
+		db = db.Where("resource_uid = ?", query.ResourceUID)
+	}
+	if query.RecordID != "" {
+		db = db.Where("record_id = ?", query.RecordID)
+	}
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if len(query.Actions) > 0 {
+		db = db.Where("action IN (?)", query.Actions)
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("created_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		db = db.Where("created_at < ?", query.Until)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	err = db.Order("created_at DESC, id DESC").Find(&entries).Error
+	return
+}
+
+// Cleanup deletes the entries older than Retention
+func (this *Auditor) Cleanup(db *aorm.DB) (deleted int64, err error) {
+	if this.Retention <= 0 {
+		return
+	}
+	db = db.New().Where("created_at < ?", time.Now().Add(-this.Retention)).Delete(&Entry{})
+	return db.RowsAffected, db.Error
+}
@@ -0,0 +1,194 @@
+package resource
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+)
+
+// RecordCache is the cache of FindOneLayout results. Values are shared between requests, so cached layouts must
+// format immutable results.
+type RecordCache interface {
+	Get(key string) (value interface{}, ok bool)
+	Set(key string, value interface{})
+	Delete(key string)
+}
+
+// LRUCache is the in memory least recently used RecordCache
+type LRUCache struct {
+	Size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+// NewLRUCache creates a new LRU cache with max size entries
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{Size: size, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func (this *LRUCache) Get(key string) (value interface{}, ok bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var el *list.Element
+	if el, ok = this.items[key]; ok {
+		this.ll.MoveToFront(el)
+		value = el.Value.(*lruEntry).value
+	}
+	return
+}
+
+func (this *LRUCache) Set(key string, value interface{}) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if el, ok := this.items[key]; ok {
+		this.ll.MoveToFront(el)
+		el.Value.(*lruEntry).value = value
+		return
+	}
+	this.items[key] = this.ll.PushFront(&lruEntry{key, value})
+	if this.Size > 0 && this.ll.Len() > this.Size {
+		el := this.ll.Back()
+		this.ll.Remove(el)
+		delete(this.items, el.Value.(*lruEntry).key)
+	}
+}
+
+func (this *LRUCache) Delete(key string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if el, ok := this.items[key]; ok {
+		this.ll.Remove(el)
+		delete(this.items, key)
+	}
+}
+
+// ResourceCache is the FindOneLayout cache config of resource. Results are memoized per request and, if Cache
+// is not nil, stored into Cache. The FIND_ONE before DB events run on cache hits, so their permission checks
+// apply, but their DB conditions don't: the cache key varies by site (tenant), locale, roles, permission mode and
+// soft deleted mode only.
+type ResourceCache struct {
+	Cache   RecordCache
+	Layouts map[string]bool
+}
+
+var cacheVersionSeq uint64
+
+// recordKey returns the cache key prefix of record ID. The key is scoped by the context tenant, or by the site
+// name if the context does not have tenant.
+func (this *ResourceCache) recordKey(ctx *core.Context, res *Resource, id aorm.ID) string {
+	scope, ok := ctx.Tenant()
+	if !ok && ctx.Site != nil {
+		scope = ctx.Site.Name()
+	}
+	return scope + "/" + res.UID + "/" + id.String()
+}
+
+// version returns the cache version of record key. Invalidate changes the version, so the entries of all key
+// variants become unreachable.
+func (this *ResourceCache) version(ctx *core.Context, recordKey string) string {
+	if v, ok := this.get(ctx, recordKey+"#version"); ok {
+		return v.(string)
+	}
+	return "0"
+}
+
+// Key returns the cache key of record ID and layout name for crud context
+func (this *ResourceCache) Key(crud *CRUD, id aorm.ID, layout string) string {
+	var (
+		ctx       = crud.context
+		recordKey = this.recordKey(ctx, crud.res.GetResource(), id)
+		locale    = ctx.Locale
+		roleNames []string
+		mode      = "allow"
+	)
+	if ctx.Translator != nil {
+		locale = ctx.GetLocale()
+	}
+	for _, role := range ctx.Roles.Interfaces() {
+		roleNames = append(roleNames, fmt.Sprint(role))
+	}
+	sort.Strings(roleNames)
+	if crud.DefaultDenyMode {
+		mode = "deny"
+	}
+	var deleted SoftDeleteMode
+	if db := ctx.DB(); db != nil {
+		deleted = SoftDeleteModeOf(db)
+	}
+	return recordKey + "@" + this.version(ctx, recordKey) + "/" + layout + "/" + locale + "/" +
+		strings.Join(roleNames, ",") + "/" + mode + "/" + strconv.Itoa(int(deleted))
+}
+
+func (this *ResourceCache) get(ctx *core.Context, key string) (value interface{}, ok bool) {
+	if value, ok = ctx.Memo().Get(key); ok {
+		return
+	}
+	if this.Cache != nil {
+		if value, ok = this.Cache.Get(key); ok {
+			ctx.Memo().Set(key, value)
+		}
+	}
+	return
+}
+
+func (this *ResourceCache) set(ctx *core.Context, key string, value interface{}) {
+	ctx.Memo().Set(key, value)
+	if this.Cache != nil {
+		this.Cache.Set(key, value)
+	}
+}
+
+// Invalidate changes the cache version of record ID, so the cached layouts of record are not used anymore
+func (this *ResourceCache) Invalidate(ctx *core.Context, res *Resource, id aorm.ID) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(atomic.AddUint64(&cacheVersionSeq, 1), 36)
+	this.set(ctx, this.recordKey(ctx, res, id)+"#version", version)
+}
+
+// SetCache enables the FindOneLayout cache of layouts. The cache entries are invalidated after update, delete,
+// restore and purge of records. If cache is nil, results are only memoized per request.
+func (res *Resource) SetCache(cache RecordCache, layouts ...string) error {
+	rc := &ResourceCache{Cache: cache, Layouts: map[string]bool{}}
+	for _, layout := range layouts {
+		rc.Layouts[layout] = true
+	}
+	res.Cache = rc
+
+	return res.OnDBActionE(func(e *DBEvent) (err error) {
+		records := e.Records()
+		if records == nil {
+			records = []interface{}{e.Result()}
+		}
+		for _, record := range records {
+			if id := res.GetKey(record); id != nil && !id.IsZero() {
+				rc.Invalidate(e.Context, res, id)
+			}
+		}
+		return
+	}, E_DB_ACTION_UPDATE.After(), E_DB_ACTION_DELETE.After(), E_DB_ACTION_UPDATE_MANY.After(),
+		E_DB_ACTION_DELETE_MANY.After(), E_DB_ACTION_RESTORE.After(), E_DB_ACTION_PURGE.After())
+}
+
+// layoutName returns the name of layout registered into resource
+func (res *Resource) layoutName(layout LayoutInterface) (name string, ok bool) {
+	for name, l := range res.Layouts {
+		if l == layout {
+			return name, true
+		}
+	}
+	return
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/roles"
+)
+
+func TestResourceCacheKey(t *testing.T) {
+	var (
+		res = New(&tenantItem{}, "", "", nil)
+		rc  = &ResourceCache{Layouts: map[string]bool{BASIC_LAYOUT: true}}
+		id  = res.GetKey(&tenantItem{ID: 1})
+	)
+	key := func(setup func(ctx *core.Context, crud *CRUD)) string {
+		ctx := core.NewContext()
+		ctx.Roles = roles.NewRoles()
+		crud := res.Crud(ctx)
+		if setup != nil {
+			setup(ctx, crud)
+		}
+		return rc.Key(crud, id, BASIC_LAYOUT)
+	}
+
+	base := key(nil)
+	cases := map[string]func(ctx *core.Context, crud *CRUD){
+		"role":   func(ctx *core.Context, crud *CRUD) { ctx.Roles.Append("admin") },
+		"locale": func(ctx *core.Context, crud *CRUD) { ctx.Locale = "pt-BR" },
+		"deny":   func(ctx *core.Context, crud *CRUD) { crud.DefaultDenyMode = true },
+	}
+	for name, setup := range cases {
+		if got := key(setup); got == base {
+			t.Errorf("%s: key must differ from %q", name, base)
+		}
+	}
+
+	if got := key(nil); got != base {
+		t.Errorf("expected stable key %q, but got %q", base, got)
+	}
+}
+
+func TestResourceCacheKeyTenant(t *testing.T) {
+	var (
+		db  = testDB(t)
+		res = New(&tenantItem{}, "", "", nil)
+		rc  = &ResourceCache{Layouts: map[string]bool{BASIC_LAYOUT: true}}
+		id  = res.GetKey(&tenantItem{ID: 1})
+		key = func(tenantID string) string {
+			return rc.Key(tenantCrud(res, db, tenantID), id, BASIC_LAYOUT)
+		}
+	)
+	if key("a") == key("b") {
+		t.Errorf("keys of tenants must differ")
+	}
+	if key("a") != key("a") {
+		t.Errorf("keys of same tenant must be equal")
+	}
+}
+
+func TestResourceCacheInvalidate(t *testing.T) {
+	var (
+		res  = New(&tenantItem{}, "", "", nil)
+		rc   = &ResourceCache{Cache: NewLRUCache(10), Layouts: map[string]bool{BASIC_LAYOUT: true}}
+		id   = res.GetKey(&tenantItem{ID: 1})
+		crud = res.Crud(core.NewContext())
+		key  = rc.Key(crud, id, BASIC_LAYOUT)
+	)
+	rc.set(crud.context, key, "cached")
+	rc.Invalidate(core.NewContext(), res, id)
+	if _, ok := rc.get(core.NewContext(), rc.Key(res.Crud(core.NewContext()), id, BASIC_LAYOUT)); ok {
+		t.Errorf("invalidated entry must not be found")
+	}
+}
+
+func TestFindOneLayoutCacheRunsBeforeEvents(t *testing.T) {
+	db := testDB(t, &tenantItem{})
+	res := New(&tenantItem{}, "", "", nil)
+	if err := res.SetCache(NewLRUCache(10), BASIC_LAYOUT); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		errDenied = errors.New("denied")
+		deny      bool
+	)
+	if err := res.OnDBActionE(func(e *DBEvent) error {
+		if deny {
+			return errDenied
+		}
+		return nil
+	}, E_DB_ACTION_FIND_ONE.Before()); err != nil {
+		t.Fatal(err)
+	}
+
+	record := &tenantItem{Name: "a"}
+	if err := res.Crud(testContext(db)).CallCreate(record); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := res.Crud(testContext(db)).FindOneLayout(res.GetKey(record), BASIC_LAYOUT); err != nil {
+		t.Fatal(err)
+	}
+	deny = true
+	if _, err := res.Crud(testContext(db)).FindOneLayout(res.GetKey(record), BASIC_LAYOUT); err == nil {
+		t.Errorf("cache hit: expected error %v", errDenied)
+	}
+}
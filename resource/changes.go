@@ -0,0 +1,214 @@
+package resource
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/go-aorm/aorm"
+)
+
+// MetaChange is the change of a record field. Meta is nil if the resource doesn't have a meta for the field.
+type MetaChange struct {
+	Meta  Metaor
+	Field *aorm.StructField
+	Old   interface{}
+	New   interface{}
+}
+
+type MetaChanges []*MetaChange
+
+// Get returns the change of field or meta name
+func (this MetaChanges) Get(name string) *MetaChange {
+	for _, c := range this {
+		if c.Field.Name == name || (c.Meta != nil && c.Meta.GetName() == name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Has returns if field or meta name was changed
+func (this MetaChanges) Has(name string) bool {
+	return this.Get(name) != nil
+}
+
+// Columns returns the DB names of changed fields
+func (this MetaChanges) Columns() (columns []string) {
+	for _, c := range this {
+		columns = append(columns, c.Field.DBName)
+	}
+	return
+}
+
+// Snapshot returns a deep copy of record, so in place changes of pointers, slices and maps of record don't change
+// the snapshot. Values implementing both driver.Valuer and sql.Scanner are copied by scanning their DB value, other
+// unexported fields are copied as is.
+func Snapshot(record interface{}) interface{} {
+	value := reflect.Indirect(reflect.ValueOf(record))
+	snapshot := reflect.New(value.Type())
+	deepCopier{}.copy(snapshot.Elem(), value)
+	return snapshot.Interface()
+}
+
+type pointerKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// deepCopier copies values keeping the copies of visited pointers, so cyclic references are copied once
+type deepCopier map[pointerKey]reflect.Value
+
+func (this deepCopier) copy(dst, src reflect.Value) {
+	if copyDBValue(dst, src) {
+		return
+	}
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		key := pointerKey{src.Pointer(), src.Type()}
+		if ptr, ok := this[key]; ok {
+			dst.Set(ptr)
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		this[key] = ptr
+		this.copy(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		this.copy(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Struct:
+		dst.Set(src)
+		typ := src.Type()
+		for i := 0; i < src.NumField(); i++ {
+			if typ.Field(i).PkgPath == "" {
+				this.copy(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			this.copy(slice.Index(i), src.Index(i))
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			this.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			this.copy(value, src.MapIndex(key))
+			m.SetMapIndex(key, value)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// copyDBValue copies src to dst scanning the driver.Valuer value of src, if src type is a Valuer and Scanner
+func copyDBValue(dst, src reflect.Value) bool {
+	if src.Kind() == reflect.Ptr || !reflect.PtrTo(src.Type()).Implements(scannerType) {
+		return false
+	}
+	value, ok, err := dbValue(src)
+	if !ok || err != nil {
+		return false
+	}
+	ptr := reflect.New(src.Type())
+	if err = ptr.Interface().(sql.Scanner).Scan(value); err != nil {
+		return false
+	}
+	dst.Set(ptr.Elem())
+	return true
+}
+
+// dbValue returns the driver.Valuer value of v and if v is a Valuer
+func dbValue(v reflect.Value) (value driver.Value, ok bool, err error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return
+	}
+	var valuer driver.Valuer
+	if valuer, ok = v.Interface().(driver.Valuer); !ok && v.Kind() != reflect.Ptr {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		valuer, ok = ptr.Interface().(driver.Valuer)
+	}
+	if ok {
+		value, err = valuer.Value()
+	}
+	return
+}
+
+// fieldsEqual returns if field values are equal. Values implementing driver.Valuer are compared by their DB
+// values.
+func fieldsEqual(old, new reflect.Value) bool {
+	ov, ok, oerr := dbValue(old)
+	if ok {
+		nv, _, nerr := dbValue(new)
+		if oerr == nil && nerr == nil {
+			return reflect.DeepEqual(ov, nv)
+		}
+	}
+	return reflect.DeepEqual(old.Interface(), new.Interface())
+}
+
+// RecordChanges returns the changed normal fields between old and new records
+func RecordChanges(res Resourcer, old, new interface{}) (changes MetaChanges) {
+	var (
+		oldValue = reflect.Indirect(reflect.ValueOf(old))
+		newValue = reflect.Indirect(reflect.ValueOf(new))
+		metas    map[string]Metaor
+	)
+
+	for _, field := range res.GetModelStruct().StructFields {
+		if field.IsIgnored || !field.IsNormal {
+			continue
+		}
+		var (
+			ov = oldValue.FieldByIndex(field.StructIndex)
+			nv = newValue.FieldByIndex(field.StructIndex)
+		)
+		if fieldsEqual(ov, nv) {
+			continue
+		}
+		if metas == nil {
+			metas = metasByFieldName(res)
+		}
+		changes = append(changes, &MetaChange{Meta: metas[field.Name], Field: field, Old: ov.Interface(), New: nv.Interface()})
+	}
+	return
+}
+
+func metasByFieldName(res Resourcer) (metas map[string]Metaor) {
+	metas = map[string]Metaor{}
+	if _, ok := res.(*Resource); ok {
+		// base resource does not have metas
+		return
+	}
+	for _, meta := range res.GetMetas([]string{}) {
+		metas[meta.GetFieldName()] = meta
+	}
+	return
+}
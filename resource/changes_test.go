@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type changesCodes struct {
+	codes []string
+}
+
+func (this changesCodes) Value() (driver.Value, error) {
+	return strings.Join(this.codes, ","), nil
+}
+
+func (this *changesCodes) Scan(src interface{}) error {
+	this.codes = strings.Split(src.(string), ",")
+	return nil
+}
+
+type changesNode struct {
+	Name   string
+	Parent *changesNode
+}
+
+type changesItem struct {
+	ID    int64 `aorm:"primary_key"`
+	Name  *string
+	Tags  []string
+	Codes changesCodes
+	Node  *changesNode
+}
+
+func TestRecordChanges(t *testing.T) {
+	res := New(&changesItem{}, "", "", nil)
+	name := "a"
+	newItem := func() *changesItem {
+		node := &changesNode{Name: "node"}
+		node.Parent = node
+		return &changesItem{ID: 1, Name: &name, Tags: []string{"a"}, Codes: changesCodes{[]string{"a"}}, Node: node}
+	}
+
+	cases := []struct {
+		change func(item *changesItem)
+		want   string
+	}{
+		{func(item *changesItem) {}, ""},
+		{func(item *changesItem) { *item.Name = "b" }, "Name"},
+		{func(item *changesItem) { item.Tags[0] = "b" }, "Tags"},
+		{func(item *changesItem) { item.Codes.codes[0] = "b" }, "Codes"},
+	}
+
+	for i, c := range cases {
+		name = "a"
+		var (
+			item = newItem()
+			old  = Snapshot(item)
+		)
+		if snapshotNode := old.(*changesItem).Node; snapshotNode == item.Node || snapshotNode.Parent != snapshotNode {
+			t.Errorf("#%d: cyclic pointer not copied", i)
+		}
+		c.change(item)
+		var names []string
+		for _, change := range RecordChanges(res, old, item) {
+			names = append(names, change.Field.Name)
+		}
+		if got := strings.Join(names, ","); got != c.want {
+			t.Errorf("#%d: expected changes %q, but got %q", i, c.want, got)
+		}
+	}
+}
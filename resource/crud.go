@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/moisespsena-go/edis"
 	errwrap "github.com/moisespsena-go/error-wrap"
@@ -26,6 +27,8 @@ type CRUD struct {
 	parent            *CRUD
 	layout            LayoutInterface
 	customLayout      bool
+	page              *Page
+	sorts             []PageOrder
 	Chan              interface{}
 	Recorde           interface{}
 	HasPermissionFunc func(mode roles.PermissionMode, ctx *core.Context) roles.Perm
@@ -83,11 +86,12 @@ func (this *CRUD) Dispatcher(dis ...edis.EventDispatcherInterface) *CRUD {
 	return this
 }
 
+// sub returns a copy of CRUD, so the setters don't change the receiver
 func (this *CRUD) sub() *CRUD {
-	sub := &(*this)
-	sub.dispatchers = this.dispatchers[:]
+	sub := *this
+	sub.dispatchers = this.dispatchers[:len(this.dispatchers):len(this.dispatchers)]
 	sub.parent = this
-	return sub
+	return &sub
 }
 
 func (this *CRUD) SetLayout(layout interface{}) *CRUD {
@@ -140,6 +144,22 @@ func (this *CRUD) FindOneLayout(key aorm.ID, layout ...interface{}) (result inte
 	if len(layout) > 0 {
 		this = this.SetLayout(layout[0])
 	}
+	if rc := this.res.GetResource().Cache; rc != nil && key != nil && !key.IsZero() {
+		if name, ok := this.res.GetResource().layoutName(this.layout); ok && rc.Layouts[name] {
+			cacheKey := rc.Key(this, key, name)
+			if result, ok = rc.get(this.context, cacheKey); ok {
+				if err = this.findOneBefore(); err != nil {
+					return nil, err
+				}
+				return
+			}
+			defer func() {
+				if err == nil {
+					rc.set(this.context, cacheKey, result)
+				}
+			}()
+		}
+	}
 	this = this.layout.Prepare(this)
 	slice, recorde := this.res.NewSliceRecord()
 	if err = this.FindOne(recorde, key); err != nil {
@@ -150,6 +170,13 @@ func (this *CRUD) FindOneLayout(key aorm.ID, layout ...interface{}) (result inte
 	return
 }
 
+// findOneBefore triggers the FIND_ONE before DB events, used by cache hits to check permissions
+func (this *CRUD) findOneBefore() error {
+	e := NewDBEvent(E_DB_ACTION_FIND_ONE, this.context.Clone())
+	e.SetResult(this.res.NewStruct(this.context.Site))
+	return this.triggerDBAction(e.before())
+}
+
 func (this *CRUD) FindManyLayout(layout ...interface{}) (result interface{}, err error) {
 	if len(layout) > 0 {
 		this = this.SetLayout(layout[0])
@@ -160,6 +187,11 @@ func (this *CRUD) FindManyLayout(layout ...interface{}) (result interface{}, err
 		if err = this.FindMany(slice); err != nil {
 			return nil, err
 		}
+		if preloader, ok := this.layout.(LayoutPreloader); ok && len(preloader.GetPreloads()) > 0 {
+			if err = this.preload(slice, preloader.GetPreloads()); err != nil {
+				return nil, err
+			}
+		}
 		result = this.layout.FormatResult(this, slice)
 		return result, nil
 	} else {
@@ -194,7 +226,6 @@ func (this *CRUD) FindOne(result interface{}, key ...aorm.ID) (err error) {
 		id      ID
 	)
 	context.SetRawDB(context.DB().ModelStruct(this.Resource().GetModelStruct()))
-	DB := context.DB()
 
 	var hasKey bool
 
@@ -235,8 +266,7 @@ func (this *CRUD) FindOne(result interface{}, key ...aorm.ID) (err error) {
 			return
 		}
 
-		DB = context.DB()
-		if err = DB.Where(id).First(result).Error; err != nil {
+		if err = this.repository().FindOne(this.repositoryContext(e), result, id); err != nil {
 			this.triggerDBAction(e.error(err))
 			return
 		}
@@ -249,8 +279,7 @@ func (this *CRUD) FindOne(result interface{}, key ...aorm.ID) (err error) {
 			return
 		}
 
-		DB = context.DB()
-		if err = DB.First(result).Error; err != nil {
+		if err = this.repository().FindOne(this.repositoryContext(e), result, nil); err != nil {
 			this.triggerDBAction(e.error(err))
 			return
 		}
@@ -277,9 +306,10 @@ func (this *CRUD) CountDB(result interface{}) (db *aorm.DB, err error) {
 	if err = this.triggerDBAction(e.before()); err != nil {
 		return nil, err
 	}
-	if db = e.Context.DB().Count(result); db.Error != nil {
+	db = e.Context.DB()
+	if err = this.repository().Count(this.repositoryContext(e), result); err != nil {
 		this.triggerDBAction(e.error(err))
-		return db, db.Error
+		return db, err
 	}
 
 	err = this.triggerDBAction(e.after())
@@ -302,7 +332,7 @@ func (this *CRUD) FindMany(result interface{}) (err error) {
 		if err = this.triggerDBAction(e.before()); err != nil {
 			return err
 		}
-		if err = context.DB().Count(result).Error; err != nil {
+		if err = this.repository().Count(this.repositoryContext(e), result); err != nil {
 			this.triggerDBAction(e.error(err))
 			return err
 		}
@@ -311,31 +341,38 @@ func (this *CRUD) FindMany(result interface{}) (err error) {
 		return err
 	}
 
+	if this.page != nil && this.page.WithTotal {
+		if err = this.pageTotal(); err != nil {
+			return err
+		}
+	}
+
 	e = NewDBEvent(E_DB_ACTION_FIND_MANY, context)
 	e.SetResult(result)
 
 	if err = this.triggerDBAction(e.before()); err != nil {
 		return err
 	}
-	DB := e.Context.DB()
-	if !DB.HasOrder() {
-		if !DB.HasOrder() {
-			if orders := this.res.GetModelStruct().Orders; len(orders) > 0 {
-				DB = DB.Order(orders)
-			} else if order := this.res.DefaultPrimaryKeyOrder(); order != 0 {
-				DB = DB.Set("gorm:order_by_primary_key", order.String())
-			}
+	var pageBackward bool
+	if this.page != nil {
+		var DB *aorm.DB
+		if DB, pageBackward, err = this.pagePrepare(e.Context.DB()); err != nil {
+			return err
 		}
+		e.Context.SetRawDB(DB)
 	}
 
-	DB = DB.Find(result)
-	context.DB(DB)
-
-	if err = DB.Error; err != nil {
+	if err = this.repository().FindMany(this.repositoryContext(e), result); err != nil {
 		this.triggerDBAction(e.error(err))
 		return err
 	}
 
+	if this.page != nil {
+		if err = this.pageDone(result, pageBackward); err != nil {
+			return err
+		}
+	}
+
 	err = this.triggerDBAction(e.after())
 	return err
 }
@@ -381,7 +418,6 @@ func (this *CRUD) callSave(recorde interface{}, eventName DBActionEvent, old ...
 	var (
 		context = this.context.Clone()
 		e       = NewDBEvent(eventName, context)
-		DB      = context.DB()
 	)
 
 	e.SetResult(recorde)
@@ -389,6 +425,10 @@ func (this *CRUD) callSave(recorde interface{}, eventName DBActionEvent, old ...
 	for _, e.old = range old {
 	}
 
+	if e.old == nil && eventName == E_DB_ACTION_UPDATE {
+		e.old, _ = context.RecordSnapshots().Pop(recorde)
+	}
+
 	if err = this.triggerDBAction(e.before()); err != nil {
 		return
 	}
@@ -397,15 +437,10 @@ func (this *CRUD) callSave(recorde interface{}, eventName DBActionEvent, old ...
 		return
 	}
 
-	DB = e.Context.DB()
 	if eventName == E_DB_ACTION_CREATE {
-		err = DB.Create(recorde).Error
-	} else if len(old) > 0 && old[0] != nil {
-		// .Where(old[0])
-		db := DB.Model(recorde).Opt(aorm.OptStoreBlankFields())
-		err = db.Update(recorde).Error
+		err = this.repository().Create(this.repositoryContext(e), recorde)
 	} else {
-		err = DB.Save(recorde).Error
+		err = this.repository().Update(this.repositoryContext(e), recorde)
 	}
 
 	if err != nil {
@@ -429,41 +464,137 @@ func (this *CRUD) callSave(recorde interface{}, eventName DBActionEvent, old ...
 	return
 }
 
-func (this *CRUD) CallDelete(recorde interface{}) (err error) {
+// updateChanges updates only the changed fields and the associations of record. The fields are selected by name.
+func (this *CRUD) updateChanges(DB *aorm.DB, recorde interface{}, changes MetaChanges, lock *OptimisticLock) error {
+	var selects []string
+	for _, c := range changes {
+		selects = append(selects, c.Field.Name)
+	}
+	for _, field := range this.res.GetModelStruct().StructFields {
+		if field.Relationship != nil {
+			selects = append(selects, field.Name)
+		}
+	}
+	if len(selects) == 0 {
+		if lock != nil {
+			// nothing to write, but a stale record still conflicts
+			return this.checkLock(DB, recorde, lock)
+		}
+		return nil
+	}
+	update := func(DB *aorm.DB) *aorm.DB {
+		return DB.Model(recorde).Select(selects).Opt(aorm.OptStoreBlankFields()).Update(recorde)
+	}
+	if lock != nil {
+		selects = append(selects, lock.Field.Name)
+		return this.updateLocked(DB, recorde, lock, update)
+	}
+	return this.checkUpdated(DB, update(DB), recorde)
+}
+
+// checkUpdated returns the error of update result, or aorm.ErrRecordNotFound if the update matched no rows, as
+// when the record was deleted or belongs to other tenant. Some databases (such as MySQL) report only the changed
+// rows, so the record is counted with the update conditions.
+func (this *CRUD) checkUpdated(DB, result *aorm.DB, recorde interface{}) (err error) {
+	if err = result.Error; err != nil || result.RowsAffected > 0 {
+		return
+	}
+	var count int
+	if err = DB.Model(recorde).Where(aorm.IDOf(recorde)).Count(&count).Error; err == nil && count == 0 {
+		err = aorm.ErrRecordNotFound
+	}
+	return
+}
+
+func (this *CRUD) updateLocked(DB *aorm.DB, recorde interface{}, lock *OptimisticLock, update func(DB *aorm.DB) *aorm.DB) (err error) {
+	if update == nil {
+		update = func(DB *aorm.DB) *aorm.DB {
+			return DB.Model(recorde).Opt(aorm.OptStoreBlankFields()).Update(recorde)
+		}
+	}
 	var (
-		primaryQuerySQL string
-		primaryParams   []interface{}
+		current = lock.Current(recorde)
+		restore = lock.Next(recorde)
 	)
+	DB = update(lock.Where(this.res, DB, current))
+	if err = DB.Error; err != nil {
+		restore()
+		return
+	}
+	if DB.RowsAffected == 0 {
+		restore()
+		return this.conflict(recorde)
+	}
+	return
+}
+
+// checkLock returns the conflict error if the lock value of record is not the stored value
+func (this *CRUD) checkLock(DB *aorm.DB, recorde interface{}, lock *OptimisticLock) (err error) {
+	var count int
+	if err = lock.Where(this.res, DB.Model(recorde).Where(aorm.IDOf(recorde)), lock.Current(recorde)).Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+	return this.conflict(recorde)
+}
+
+// conflict returns the conflict error of record with the stored record, or the error of find it
+func (this *CRUD) conflict(recorde interface{}) (err error) {
+	current := this.res.NewStruct(this.context.Site)
+	if err = NewCrud(this.res, this.context).FindOne(current, this.res.GetKey(recorde)); err != nil {
+		// not found, or stored into other tenant
+		return
+	}
+	return &ConflictError{Resource: this.res, Record: recorde, Current: current}
+}
+
+func (this *CRUD) CallDelete(recorde interface{}) (err error) {
+	if sd := this.res.GetResource().SoftDelete; sd != nil {
+		return this.callDelete(recorde, E_DB_ACTION_DELETE, this.context.ResourceID, SoftDeleteExclude, func(ctx *RepositoryContext) error {
+			now := time.Now()
+			return sd.Mark(this.res, ctx.DB(), recorde, &now)
+		})
+	}
+	return this.callDelete(recorde, E_DB_ACTION_DELETE, this.context.ResourceID, SoftDeleteInclude, func(ctx *RepositoryContext) error {
+		return this.repository().Delete(ctx, recorde)
+	})
+}
+
+func (this *CRUD) callDelete(recorde interface{}, eventName DBActionEvent, id aorm.ID, mode SoftDeleteMode, del func(ctx *RepositoryContext) error) (err error) {
 	defer this.recorde(recorde)()
-	if primaryQuerySQL, primaryParams, err = IdToPrimaryQuery(this.context, this.res, false, this.context.ResourceID); err != nil {
+	var (
+		e  = NewDBEvent(eventName, this.context.Clone())
+		db *aorm.DB
+	)
+	if db, err = this.res.GetResource().TenantDB(this.context, this.context.DB()); err != nil {
 		return
-	} else {
-		db := this.context.DB()
-		db = db.First(recorde, append([]interface{}{primaryQuerySQL}, primaryParams...)...)
-		if !db.RecordNotFound() {
-			e := NewDBEvent(E_DB_ACTION_DELETE, this.context)
-			e.SetResult(recorde)
+	}
+	if sd := this.res.GetResource().SoftDelete; sd != nil {
+		db = sd.Scope(this.res, db, mode)
+	}
+	e.Context.SetRawDB(db)
 
-			if err = this.triggerDBAction(e.before()); err != nil {
-				return
-			}
+	if err = this.repository().FindOne(this.repositoryContext(e), recorde, id); err != nil {
+		return
+	}
 
-			if err = this.triggerDBAction(e); err != nil {
-				return
-			}
+	e.SetResult(recorde)
 
-			if err = db.Delete(recorde).Error; err != nil {
-				if err = this.triggerDBAction(e.error(err)); err != nil {
-					return
-				}
+	if err = this.triggerDBAction(e.before()); err != nil {
+		return
+	}
 
-			}
+	if err = this.triggerDBAction(e); err != nil {
+		return
+	}
 
-			err = this.triggerDBAction(e.after())
+	if err = del(this.repositoryContext(e)); err != nil {
+		if err = this.triggerDBAction(e.error(err)); err != nil {
+			return
 		}
-		return
+
 	}
-	return aorm.ErrRecordNotFound
+
+	return this.triggerDBAction(e.after())
 }
 
 func (this *CRUD) Delete(record interface{}) (err error) {
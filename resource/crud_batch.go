@@ -0,0 +1,351 @@
+package resource
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ecletus/roles"
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+
+	"github.com/ecletus/core"
+)
+
+// BatchInsertDialects are the dialects supporting multi-row INSERT. The value is true if dialect supports
+// `INSERT ... RETURNING`, required to get generated primary keys. Multi-row INSERT is used only by aorm repository
+// on models without create callback methods, because it doesn't run the aorm create callbacks.
+var BatchInsertDialects = map[string]bool{
+	"postgres": true,
+	"mysql":    false,
+	"sqlite3":  false,
+}
+
+// BatchInsertMaxArgs is the max number of args of a multi-row INSERT statement
+var BatchInsertMaxArgs = 900
+
+// CreateMany creates records checking permission once. Records errors are returned as `core.Errors` of
+// `*BatchRecordError`.
+func (this *CRUD) CreateMany(records ...interface{}) error {
+	if this.HasPermission(roles.Create) {
+		return this.CallCreateMany(records...)
+	}
+	return roles.ErrPermissionDenied
+}
+
+func (this *CRUD) CallCreateMany(records ...interface{}) error {
+	return this.callSaveMany(E_DB_ACTION_CREATE_MANY, records)
+}
+
+// UpdateMany updates records checking permission once. Records errors are returned as `core.Errors` of
+// `*BatchRecordError`.
+func (this *CRUD) UpdateMany(records ...interface{}) error {
+	if this.HasPermission(roles.Update) {
+		return this.CallUpdateMany(records...)
+	}
+	return roles.ErrPermissionDenied
+}
+
+func (this *CRUD) CallUpdateMany(records ...interface{}) error {
+	return this.callSaveMany(E_DB_ACTION_UPDATE_MANY, records)
+}
+
+// DeleteMany deletes (or soft deletes) records of ids checking permission once. Not found ids are returned as `core.Errors` of
+// `*BatchRecordError`. It deletes using aorm, so returns ErrUnsupportedRepository if resource has other repository.
+func (this *CRUD) DeleteMany(ids ...aorm.ID) error {
+	if this.HasPermission(roles.Delete) {
+		return this.CallDeleteMany(ids...)
+	}
+	return roles.ErrPermissionDenied
+}
+
+// callSaveMany saves records inside a transaction. Each record (or multi-row INSERT chunk) runs inside a
+// savepoint, so a failed record is rolled back alone and returned as `*BatchRecordError`.
+func (this *CRUD) callSaveMany(eventName DBActionEvent, records []interface{}) (err error) {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var errs core.Errors
+
+	if err = this.Transaction(func(tx *CRUD) (err error) {
+		errs, err = tx.saveMany(eventName, records)
+		return
+	}); err != nil {
+		return
+	}
+
+	if errs.HasError() {
+		return errs
+	}
+	return nil
+}
+
+func (this *CRUD) saveMany(eventName DBActionEvent, records []interface{}) (errs core.Errors, err error) {
+	var (
+		context = this.context.Clone()
+		e       = NewDBEvent(eventName, context)
+		repo    = this.repository()
+	)
+
+	e.records = records
+	e.SetResult(records)
+
+	if err = this.triggerDBAction(e.before()); err != nil {
+		return
+	}
+
+	if err = this.triggerDBAction(e); err != nil {
+		return
+	}
+
+	var save = func(offset int, records []interface{}) {
+		for i, record := range records {
+			if err := this.Transaction(func(*CRUD) error {
+				if eventName == E_DB_ACTION_CREATE_MANY {
+					return repo.Create(this.repositoryContext(e), record)
+				}
+				if !this.res.IsSingleton() && aorm.ZeroIdOf(record) {
+					return errors.New("ID not set")
+				}
+				return repo.Update(this.repositoryContext(e), record)
+			}); err != nil {
+				errs.AddError(&BatchRecordError{offset + i, record, err})
+			}
+		}
+	}
+
+	if eventName == E_DB_ACTION_CREATE_MANY && isAormRepository(repo) && !hasCreateCallbacks(records[0]) {
+		var inserted bool
+		if inserted, err = this.batchInsert(e.Context.DB(), records, save); err != nil {
+			this.triggerDBAction(e.error(err))
+			return
+		}
+		if !inserted {
+			save(0, records)
+		}
+	} else {
+		save(0, records)
+	}
+
+	if errs.HasError() {
+		this.triggerDBAction(e.error(errs))
+		return
+	}
+
+	err = this.triggerDBAction(e.after())
+	return
+}
+
+func (this *CRUD) CallDeleteMany(ids ...aorm.ID) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+	if !isAormRepository(this.repository()) {
+		return errwrap.Wrap(ErrUnsupportedRepository, "DeleteMany of resource %q", this.res.GetID())
+	}
+
+	var (
+		context = this.context.Clone()
+		e       = NewDBEvent(E_DB_ACTION_DELETE_MANY, context)
+		DB      *aorm.DB
+		errs    core.Errors
+		slice   = this.res.NewSlicePtr()
+		found   = map[string]bool{}
+	)
+
+	if DB, err = this.res.GetResource().TenantDB(context, context.DB()); err != nil {
+		return
+	}
+	if sd := this.res.GetResource().SoftDelete; sd != nil {
+		DB = sd.Scope(this.res, DB, SoftDeleteExclude)
+	}
+
+	if err = DB.Where(aorm.InID(ids...)).Find(slice).Error; err != nil {
+		return
+	}
+
+	ResultFormatter(slice, func(i int, record interface{}) {
+		e.records = append(e.records, record)
+		found[this.res.GetKey(record).String()] = true
+	})
+
+	for i, id := range ids {
+		if !found[id.String()] {
+			errs.AddError(&BatchRecordError{Index: i, Err: aorm.ErrRecordNotFound})
+		}
+	}
+
+	if len(e.records) == 0 {
+		return errs
+	}
+
+	e.SetResult(e.records)
+
+	if err = this.triggerDBAction(e.before()); err != nil {
+		return
+	}
+
+	if err = this.triggerDBAction(e); err != nil {
+		return
+	}
+
+	var foundIds = make([]aorm.ID, len(e.records))
+	for i, record := range e.records {
+		foundIds[i] = this.res.GetKey(record)
+	}
+
+	DB = e.Context.DB().Where(aorm.InID(foundIds...))
+	if sd := this.res.GetResource().SoftDelete; sd != nil {
+		now := time.Now()
+		if err = DB.Model(this.res.NewStruct(context.Site)).UpdateColumn(sd.Field.DBName, now).Error; err == nil {
+			for _, record := range e.records {
+				reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(sd.Field.StructIndex).Set(reflect.ValueOf(&now))
+			}
+		}
+	} else {
+		err = DB.Delete(this.res.NewStruct(context.Site)).Error
+	}
+
+	if err != nil {
+		this.triggerDBAction(e.error(err))
+		return
+	}
+
+	if err = this.triggerDBAction(e.after()); err != nil {
+		return
+	}
+
+	if errs.HasError() {
+		return errs
+	}
+	return nil
+}
+
+// batchInsert inserts records using multi-row INSERT statements if dialect supports it. Each statement runs inside
+// a savepoint; if it fails, the records of statement are saved one by one by fallback to collect their errors.
+func (this *CRUD) batchInsert(DB *aorm.DB, records []interface{}, fallback func(offset int, records []interface{})) (ok bool, err error) {
+	returning, supported := BatchInsertDialects[DB.Dialect().GetName()]
+	if !supported {
+		return
+	}
+
+	var (
+		modelStruct   = this.res.GetModelStruct()
+		primaryFields = this.res.GetPrimaryFields()
+		zeroID        bool
+		fields        []*aorm.StructField
+		columns       []string
+		scope         = DB.NewScope(records[0])
+		now           = time.Now()
+	)
+
+	for _, record := range records {
+		if aorm.ZeroIdOf(record) {
+			zeroID = true
+			break
+		}
+	}
+
+	if zeroID && (!returning || len(primaryFields) != 1) {
+		return
+	}
+
+	for _, field := range modelStruct.StructFields {
+		if field.IsIgnored || !field.IsNormal || (zeroID && field.IsPrimaryKey) {
+			continue
+		}
+		fields = append(fields, field)
+		columns = append(columns, scope.Quote(field.DBName))
+	}
+
+	var (
+		perStatement = BatchInsertMaxArgs / len(fields)
+		head         = "INSERT INTO " + scope.QuotedTableName() + " (" + strings.Join(columns, ", ") + ") VALUES "
+		placeholders = "(" + strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ") + ")"
+	)
+
+	if perStatement == 0 {
+		perStatement = 1
+	}
+
+	for start := 0; start < len(records); start += perStatement {
+		var (
+			end    = start + perStatement
+			values []string
+			args   []interface{}
+		)
+		if end > len(records) {
+			end = len(records)
+		}
+
+		for _, record := range records[start:end] {
+			recordValue := reflect.Indirect(reflect.ValueOf(record))
+			for _, field := range fields {
+				value := recordValue.FieldByIndex(field.StructIndex)
+				if (field.Name == "CreatedAt" || field.Name == "UpdatedAt") && value.Type() == reflect.TypeOf(now) && value.Interface().(time.Time).IsZero() {
+					value.Set(reflect.ValueOf(now))
+				}
+				args = append(args, value.Interface())
+			}
+			values = append(values, placeholders)
+		}
+
+		var (
+			query = head + strings.Join(values, ", ")
+			chunk = records[start:end]
+		)
+
+		if chunkErr := this.Transaction(func(*CRUD) error {
+			if !zeroID {
+				return DB.Exec(query, args...).Error
+			}
+			return this.batchInsertReturning(DB, query+" RETURNING "+scope.Quote(primaryFields[0].DBName), args, chunk, primaryFields[0])
+		}); chunkErr != nil {
+			fallback(start, chunk)
+		}
+	}
+	return true, nil
+}
+
+// createCallbackMethods are the model methods called by aorm on create, skipped by multi-row INSERT
+var createCallbackMethods = []string{"BeforeSave", "BeforeCreate", "AfterCreate", "AfterSave"}
+
+// hasCreateCallbacks returns if record model has aorm create callback methods
+func hasCreateCallbacks(record interface{}) bool {
+	typ := reflect.TypeOf(record)
+	if typ.Kind() != reflect.Ptr {
+		typ = reflect.PtrTo(typ)
+	}
+	for _, name := range createCallbackMethods {
+		if _, ok := typ.MethodByName(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isAormRepository(repo RecordRepository) bool {
+	switch repo.(type) {
+	case AormRepository, *AormRepository:
+		return true
+	}
+	return false
+}
+
+func (this *CRUD) batchInsertReturning(DB *aorm.DB, query string, args []interface{}, records []interface{}, primaryField *aorm.StructField) (err error) {
+	rows, err := DB.Raw(query, args...).Rows()
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		pk := reflect.Indirect(reflect.ValueOf(records[i])).FieldByIndex(primaryField.StructIndex)
+		if err = rows.Scan(pk.Addr().Interface()); err != nil {
+			return
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,237 @@
+package resource
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-aorm/aorm"
+)
+
+// PageOrder is a keyset pagination order field
+type PageOrder struct {
+	Field *aorm.StructField
+	Desc  bool
+}
+
+// Page is the keyset pagination option of CRUD.FindMany. Records are ordered by the CRUD.Query sort, or by
+// Resource.PageOrder, followed by the primary fields ordered by Resource.DefaultPrimaryKeyOrder. After FindMany, the
+// Next and Prev opaque cursors are set, or blank if there are no more records. Cursors are valid only for the same
+// order. If WithTotal is true, Total is set to the count of all records.
+type Page struct {
+	Size      int
+	Cursor    string
+	WithTotal bool
+
+	Next  string
+	Prev  string
+	Total int
+}
+
+type pageCursor struct {
+	Order    string            `json:"o"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// SetPageOrder sets the keyset pagination order fields. Prefix the field name with "-" to descending order.
+func (res *Resource) SetPageOrder(fields ...string) error {
+	var orders []PageOrder
+	for _, name := range fields {
+		order := PageOrder{}
+		if strings.HasPrefix(name, "-") {
+			order.Desc = true
+			name = name[1:]
+		}
+		field, ok := res.ModelStruct.FieldsByName[name]
+		if !ok {
+			return fmt.Errorf("%v is not a valid field for resource %v", name, res.Name)
+		}
+		order.Field = field
+		orders = append(orders, order)
+	}
+	res.PageOrder = orders
+	return nil
+}
+
+// GetPageOrder returns the keyset pagination order fields, including the primary fields
+func (res *Resource) GetPageOrder() []PageOrder {
+	return res.pageOrder(res.PageOrder)
+}
+
+// pageOrder returns the order fields followed by the missing primary fields
+func (res *Resource) pageOrder(fields []PageOrder) (orders []PageOrder) {
+	orders = append(orders, fields...)
+main:
+	for _, field := range res.PrimaryFields {
+		for _, order := range orders {
+			if order.Field.DBName == field.DBName {
+				continue main
+			}
+		}
+		orders = append(orders, PageOrder{Field: field, Desc: res.defaultPrimaryKeyOrder == aorm.DESC})
+	}
+	return
+}
+
+// Page sets the keyset pagination option of FindMany
+func (this *CRUD) Page(page *Page) *CRUD {
+	this = this.sub()
+	this.page = page
+	return this
+}
+
+// pageOrder returns the keyset pagination order fields of the query sort, or the default ones
+func (this *CRUD) pageOrder() []PageOrder {
+	if len(this.sorts) == 0 {
+		return this.res.GetResource().GetPageOrder()
+	}
+	return this.res.GetResource().pageOrder(this.sorts)
+}
+
+// pageOrderKey returns the order identifier of the cursor
+func pageOrderKey(orders []PageOrder) string {
+	names := make([]string, len(orders))
+	for i, order := range orders {
+		names[i] = order.Field.DBName
+		if order.Desc {
+			names[i] = "-" + names[i]
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (this *CRUD) pageTotal() (err error) {
+	var (
+		crud    = *this
+		context = this.context.Clone()
+	)
+	context.SetRawDB(context.DB().Set("qor:getting_total_count", true))
+	crud.context = context
+	crud.page = nil
+	return crud.FindMany(&this.page.Total)
+}
+
+// pagePrepare applies the cursor condition, the order and the limit to DB. The DB orders are replaced by the page
+// order.
+func (this *CRUD) pagePrepare(DB *aorm.DB) (_ *aorm.DB, backward bool, err error) {
+	var (
+		orders = this.pageOrder()
+		scope  = DB.NewScope(this.res.GetValue())
+		cursor pageCursor
+	)
+
+	if this.page.Cursor != "" {
+		var b []byte
+		if b, err = base64.RawURLEncoding.DecodeString(this.page.Cursor); err == nil {
+			err = json.Unmarshal(b, &cursor)
+		}
+		if err == nil && cursor.Order != pageOrderKey(orders) {
+			err = fmt.Errorf("made for a different order")
+		} else if err == nil && len(cursor.Values) != len(orders) {
+			err = fmt.Errorf("bad values count")
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("resource: invalid page cursor: %v", err)
+		}
+
+		var (
+			ors  []string
+			args []interface{}
+		)
+
+		for i := range orders {
+			var ands []string
+			for j, order := range orders[0 : i+1] {
+				value := reflect.New(order.Field.Struct.Type)
+				if err = json.Unmarshal(cursor.Values[j], value.Interface()); err != nil {
+					return nil, false, fmt.Errorf("resource: invalid page cursor: %v", err)
+				}
+				op := "="
+				if j == i {
+					if order.Desc != cursor.Backward {
+						op = "<"
+					} else {
+						op = ">"
+					}
+				}
+				ands = append(ands, fmt.Sprintf("%v.%v %v ?", scope.FromName(), scope.Quote(order.Field.DBName), op))
+				args = append(args, value.Elem().Interface())
+			}
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+		DB = DB.Where(strings.Join(ors, " OR "), args...)
+	}
+
+	for i, order := range orders {
+		dir := "ASC"
+		if order.Desc != cursor.Backward {
+			dir = "DESC"
+		}
+		DB = DB.Order(fmt.Sprintf("%v.%v %v", scope.FromName(), scope.Quote(order.Field.DBName), dir), i == 0)
+	}
+
+	if this.page.Size > 0 {
+		DB = DB.Limit(this.page.Size + 1)
+	}
+	return DB, cursor.Backward, nil
+}
+
+// pageDone truncates and reorders the result and sets the page cursors
+func (this *CRUD) pageDone(result interface{}, backward bool) (err error) {
+	var (
+		slice   = reflect.Indirect(reflect.ValueOf(result))
+		hasMore = this.page.Size > 0 && slice.Len() > this.page.Size
+	)
+
+	if hasMore {
+		slice.Set(slice.Slice(0, this.page.Size))
+	}
+
+	if backward {
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			a, b := slice.Index(i).Interface(), slice.Index(j).Interface()
+			slice.Index(i).Set(reflect.ValueOf(b))
+			slice.Index(j).Set(reflect.ValueOf(a))
+		}
+	}
+
+	this.page.Next, this.page.Prev = "", ""
+
+	if slice.Len() == 0 {
+		return
+	}
+
+	if (!backward && hasMore) || (backward && this.page.Cursor != "") {
+		if this.page.Next, err = this.pageCursor(slice.Index(slice.Len()-1), false); err != nil {
+			return
+		}
+	}
+
+	if (backward && hasMore) || (!backward && this.page.Cursor != "") {
+		this.page.Prev, err = this.pageCursor(slice.Index(0), true)
+	}
+	return
+}
+
+func (this *CRUD) pageCursor(record reflect.Value, backward bool) (_ string, err error) {
+	var (
+		orders = this.pageOrder()
+		cursor = pageCursor{Order: pageOrderKey(orders), Backward: backward}
+	)
+	record = reflect.Indirect(record)
+	for _, order := range orders {
+		var b []byte
+		if b, err = json.Marshal(record.FieldByIndex(order.Field.StructIndex).Interface()); err != nil {
+			return
+		}
+		cursor.Values = append(cursor.Values, b)
+	}
+	var b []byte
+	if b, err = json.Marshal(cursor); err != nil {
+		return
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// metasResource is a resource with the metas of model fields
+type metasResource struct {
+	*Resource
+	metas []Metaor
+}
+
+func (this *metasResource) GetMetas([]string) []Metaor {
+	return this.metas
+}
+
+func newMetasResource(value interface{}, names ...string) *metasResource {
+	res := &metasResource{Resource: New(value, "", "", nil)}
+	for _, name := range names {
+		res.metas = append(res.metas, &Meta{MetaName: &MetaName{Name: name}, FieldName: name,
+			FieldStruct: res.ModelStruct.FieldsByName[name]})
+	}
+	return res
+}
+
+type pageItem struct {
+	ID   int64 `aorm:"primary_key"`
+	Name string
+}
+
+func TestPageQuerySort(t *testing.T) {
+	db := testDB(t, &pageItem{})
+	res := newMetasResource(&pageItem{}, "Name")
+	ctx := testContext(db)
+	for _, name := range []string{"e", "d", "c", "b", "a"} {
+		if err := NewCrud(res, ctx).Create(&pageItem{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(items []pageItem) string {
+		var s []string
+		for _, item := range items {
+			s = append(s, item.Name)
+		}
+		return strings.Join(s, ",")
+	}
+
+	crud, err := NewCrud(res, testContext(db)).Query(url.Values{"sort": {"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &Page{Size: 2}
+	var items []pageItem
+	if err = crud.Page(page).FindMany(&items); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(items); got != "a,b" {
+		t.Errorf("page 1: expected a,b, but got %v", got)
+	}
+
+	page = &Page{Size: 2, Cursor: page.Next}
+	items = nil
+	if err = crud.Page(page).FindMany(&items); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(items); got != "c,d" {
+		t.Errorf("page 2: expected c,d, but got %v", got)
+	}
+
+	page = &Page{Size: 2, Cursor: page.Next}
+	items = nil
+	if err = NewCrud(res, testContext(db)).Page(page).FindMany(&items); err == nil || !strings.Contains(err.Error(), "invalid page cursor") {
+		t.Errorf("cursor of other order: expected invalid cursor error, but got %v", err)
+	}
+}
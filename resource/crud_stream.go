@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// Stream finds many records and calls f with each record formatted by the CRUD layout, reading one row at a
+// time. The FIND_MANY DB events are triggered with an empty result slice. Stream stops when f returns an error,
+// ctx is done or the CRUD context has errors. If DB has no order, records are ordered by resource page order.
+// Stream reads the rows from aorm, so it returns ErrUnsupportedRepository if resource has other repository.
+func (this *CRUD) Stream(ctx context.Context, f func(record interface{}) error) (err error) {
+	if !isAormRepository(this.repository()) {
+		return errwrap.Wrap(ErrUnsupportedRepository, "Stream of resource %q", this.res.GetID())
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crud := this
+	if crud.layout != nil {
+		crud = crud.layout.Prepare(crud)
+	}
+
+	var (
+		crudCtx = crud.context.Clone()
+		e       *DBEvent
+		rows    *sql.Rows
+	)
+	crudCtx.SetRawDB(crudCtx.DB().ModelStruct(crud.res.GetModelStruct()))
+
+	if len(crudCtx.ExcludeResourceID) > 0 {
+		crudCtx.SetRawDB(crudCtx.DB().Where(aorm.InID(crudCtx.ExcludeResourceID...).Exclude()))
+	}
+
+	e = NewDBEvent(E_DB_ACTION_FIND_MANY, crudCtx)
+	e.SetResult(crud.res.NewSlicePtr())
+
+	if err = crud.triggerDBAction(e.before()); err != nil {
+		return
+	}
+
+	DB := e.Context.DB()
+	if crud.page != nil {
+		if DB, _, err = crud.pagePrepare(DB); err != nil {
+			return
+		}
+	} else if !DB.HasOrder() {
+		if orders := crud.res.GetModelStruct().Orders; len(orders) > 0 {
+			DB = DB.Order(orders)
+		} else {
+			scope := DB.NewScope(crud.res.GetValue())
+			for _, order := range crud.res.GetResource().GetPageOrder() {
+				dir := "ASC"
+				if order.Desc {
+					dir = "DESC"
+				}
+				DB = DB.Order(fmt.Sprintf("%v.%v %v", scope.FromName(), scope.Quote(order.Field.DBName), dir))
+			}
+		}
+	}
+
+	if rows, err = DB.Model(crud.res.GetValue()).Rows(); err != nil {
+		crud.triggerDBAction(e.error(err))
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if err = crudCtx.CheckErr(); err != nil {
+			break
+		}
+
+		slice, record := crud.res.NewSliceRecord()
+		if err = DB.ScanRows(rows, record); err != nil {
+			break
+		}
+
+		var result interface{} = record
+		if crud.layout != nil {
+			result = reflect.ValueOf(crud.layout.FormatResult(crud, slice)).Index(0).Interface()
+		}
+		if err = f(result); err != nil {
+			return
+		}
+	}
+
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		crud.triggerDBAction(e.error(err))
+		return
+	}
+	return crud.triggerDBAction(e.after())
+}
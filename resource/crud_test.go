@@ -0,0 +1,22 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/ecletus/core"
+)
+
+func TestCRUDSubDoesNotChangeReceiver(t *testing.T) {
+	var (
+		res  = New(&tenantItem{}, "", "", nil)
+		ctx  = core.NewContext()
+		crud = res.Crud(ctx)
+		sub  = crud.SetContext(core.NewContext())
+	)
+	if crud.Context() != ctx {
+		t.Errorf("SetContext changed the receiver context")
+	}
+	if sub == crud || sub.Parent() != crud {
+		t.Errorf("SetContext must return a sub CRUD")
+	}
+}
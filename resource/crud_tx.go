@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// TX_DEPTH_KEY is the DB setting key of the CRUD transaction depth. Zero is the root transaction.
+var TX_DEPTH_KEY = pkg + ".tx_depth"
+
+// TxDepth returns the transaction depth of db and if db is inside a CRUD transaction
+func TxDepth(db *aorm.DB) (depth int, ok bool) {
+	var v interface{}
+	if v, ok = db.Get(TX_DEPTH_KEY); ok {
+		depth = v.(int)
+	}
+	return
+}
+
+// TX_COMMIT_HOOKS_KEY is the DB setting key of the funcs called after the root CRUD transaction commits
+var TX_COMMIT_HOOKS_KEY = pkg + ".tx_commit_hooks"
+
+type txCommitHooks struct {
+	db    *aorm.DB
+	funcs []func(db *aorm.DB)
+}
+
+func txCommitHooksOf(db *aorm.DB) *txCommitHooks {
+	if v, ok := db.Get(TX_COMMIT_HOOKS_KEY); ok {
+		return v.(*txCommitHooks)
+	}
+	return nil
+}
+
+// AfterCommit registers f to be called with the DB outside of transaction after the root CRUD transaction of db
+// commits. The funcs registered inside a rolled back savepoint or transaction are discarded. Returns false if db is
+// not inside a CRUD transaction, as a transaction began by the application.
+func AfterCommit(db *aorm.DB, f func(db *aorm.DB)) bool {
+	if hooks := txCommitHooksOf(db); hooks != nil {
+		hooks.funcs = append(hooks.funcs, f)
+		return true
+	}
+	return false
+}
+
+// sqlTx is the DB of transactions, such as *sql.Tx
+type sqlTx interface {
+	Commit() error
+	Rollback() error
+}
+
+// InTransaction returns if CRUD runs inside a transaction
+func (this *CRUD) InTransaction() bool {
+	return IsTransaction(this.context.DB())
+}
+
+// IsTransaction returns if db is inside a CRUD transaction or a transaction began by the application
+func IsTransaction(db *aorm.DB) bool {
+	if _, ok := TxDepth(db); ok {
+		return true
+	}
+	_, ok := db.CommonDB().(sqlTx)
+	return ok
+}
+
+// Transaction runs f inside a transaction attached to a clone of CRUD context, so nested `Crud(ctx)` calls and DB
+// event handlers use it. The transaction is committed if f returns nil, otherwise rolled back. Nested calls use
+// savepoints, as calls with a context DB that is a transaction began by the application.
+func (this *CRUD) Transaction(f func(tx *CRUD) error) (err error) {
+	var (
+		context   = this.context.Clone()
+		db        = context.DB()
+		savepoint string
+		hooks     = txCommitHooksOf(db)
+		mark      int
+	)
+
+	depth, ok := TxDepth(db)
+	if !ok {
+		// transaction began by the application
+		_, ok = db.CommonDB().(sqlTx)
+	}
+
+	if ok {
+		depth++
+		savepoint = fmt.Sprintf("crud_tx_%d", depth)
+		if err = db.Exec("SAVEPOINT " + savepoint).Error; err != nil {
+			return errwrap.Wrap(err, "create savepoint %q", savepoint)
+		}
+		db = db.Set(TX_DEPTH_KEY, depth)
+		if hooks != nil {
+			mark = len(hooks.funcs)
+		}
+	} else {
+		if db = db.Begin(); db.Error != nil {
+			return errwrap.Wrap(db.Error, "begin transaction")
+		}
+		hooks = &txCommitHooks{db: context.DB()}
+		db = db.Set(TX_DEPTH_KEY, 0).Set(TX_COMMIT_HOOKS_KEY, hooks)
+	}
+
+	context.SetDB(db)
+
+	var (
+		tx       = *this
+		rollback = func() error {
+			if hooks != nil {
+				hooks.funcs = hooks.funcs[:mark]
+			}
+			if savepoint != "" {
+				return db.Exec("ROLLBACK TO SAVEPOINT " + savepoint).Error
+			}
+			return db.Rollback().Error
+		}
+	)
+
+	tx.context = context
+	tx.parent = this
+
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+
+	if err = f(&tx); err != nil {
+		if rerr := rollback(); rerr != nil {
+			log.Errorf("CRUD.Transaction of resource %q: rollback failed: %v", this.res.GetID(), rerr)
+		}
+		return
+	}
+
+	if savepoint != "" {
+		if err = db.Exec("RELEASE SAVEPOINT " + savepoint).Error; err != nil {
+			err = errwrap.Wrap(err, "release savepoint %q", savepoint)
+		}
+		return
+	}
+	if err = db.Commit().Error; err != nil {
+		return errwrap.Wrap(err, "commit transaction")
+	}
+	for _, f := range hooks.funcs {
+		f(hooks.db)
+	}
+	return
+}
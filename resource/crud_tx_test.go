@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-aorm/aorm"
+)
+
+func TestTransaction(t *testing.T) {
+	var (
+		db      = testDB(t, &tenantItem{})
+		res     = New(&tenantItem{}, "", "", nil)
+		failure = errors.New("failure")
+		count   = func() (n int) {
+			if err := db.Model(&tenantItem{}).Count(&n).Error; err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	)
+
+	// nested: the failed savepoint is rolled back alone
+	err := res.Crud(testContext(db)).Transaction(func(tx *CRUD) error {
+		if err := tx.Create(&tenantItem{Name: "outer"}); err != nil {
+			return err
+		}
+		if err := tx.Transaction(func(tx *CRUD) error {
+			if !tx.InTransaction() {
+				t.Error("nested: expected in transaction")
+			}
+			if err := tx.Create(&tenantItem{Name: "inner"}); err != nil {
+				return err
+			}
+			return failure
+		}); err != failure {
+			t.Errorf("nested: expected %v, but got %v", failure, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("nested: expected 1 record, but got %d", n)
+	}
+
+	// external: the transaction began by the application uses savepoints and is not committed
+	ext := db.Begin()
+	if ext.Error != nil {
+		t.Fatal(ext.Error)
+	}
+	crud := res.Crud(testContext(ext))
+	if !crud.InTransaction() {
+		t.Error("external: expected in transaction")
+	}
+	if err = crud.Transaction(func(tx *CRUD) error {
+		return tx.Create(&tenantItem{Name: "external"})
+	}); err != nil {
+		t.Fatalf("external: unexpected error: %v", err)
+	}
+	if err = crud.Transaction(func(tx *CRUD) error {
+		if err := tx.Create(&tenantItem{Name: "external failed"}); err != nil {
+			return err
+		}
+		return failure
+	}); err != failure {
+		t.Errorf("external: expected %v, but got %v", failure, err)
+	}
+	var n int
+	if err = ext.Model(&tenantItem{}).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("external: expected 2 records inside transaction, but got %d", n)
+	}
+	if err = ext.Rollback().Error; err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("external: expected 1 record after rollback, but got %d", n)
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	var (
+		db      = testDB(t, &tenantItem{})
+		crud    = New(&tenantItem{}, "", "", nil).Crud(testContext(db))
+		failure = errors.New("failure")
+		called  []string
+		hook    = func(name string) func(db *aorm.DB) {
+			return func(db *aorm.DB) {
+				if IsTransaction(db) {
+					t.Errorf("%s: expected DB outside of transaction", name)
+				}
+				called = append(called, name)
+			}
+		}
+	)
+
+	if AfterCommit(db, hook("outside")) {
+		t.Error("outside: expected not registered")
+	}
+
+	err := crud.Transaction(func(tx *CRUD) error {
+		AfterCommit(tx.DB(), hook("outer"))
+		tx.Transaction(func(tx *CRUD) error {
+			AfterCommit(tx.DB(), hook("rolled back savepoint"))
+			return failure
+		})
+		return tx.Transaction(func(tx *CRUD) error {
+			AfterCommit(tx.DB(), hook("released savepoint"))
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crud.Transaction(func(tx *CRUD) error {
+		AfterCommit(tx.DB(), hook("rolled back"))
+		return failure
+	})
+
+	if want := []string{"outer", "released savepoint"}; !reflect.DeepEqual(called, want) {
+		t.Errorf("expected calls %v, but got %v", want, called)
+	}
+}
@@ -0,0 +1,30 @@
+package resource
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ecletus/core"
+	testutils "github.com/ecletus/core/test/utils"
+	"github.com/go-aorm/aorm"
+)
+
+// testDB returns the test database with the tables of values recreated. The DB tests are skipped unless TEST_DB
+// env is set (see test/utils.TestDB).
+func testDB(t *testing.T, values ...interface{}) *aorm.DB {
+	if os.Getenv("TEST_DB") == "" {
+		t.Skip("TEST_DB not set")
+	}
+	db := testutils.TestDB()
+	for _, value := range values {
+		if err := db.DropTableIfExists(value).AutoMigrate(value).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// testContext returns a new context with db
+func testContext(db *aorm.DB) *core.Context {
+	return core.NewContext().SetDB(db)
+}
@@ -1,10 +1,6 @@
 package resource
 
-import (
-	"strings"
-
-	"github.com/ecletus/core"
-)
+import "github.com/ecletus/core"
 
 type Decoder struct {
 	defaultDenyMode bool
@@ -40,13 +36,7 @@ func (this Decoder) Decode(result interface{}) (err error) {
 
 	metaors := this.res.GetContextMetas(this.context)
 
-	if strings.Contains(this.context.Request.Header.Get("Content-Type"), "json") {
-		defer this.context.Request.Body.Close()
-		metaValues, err = ConvertJSONToMetaValues(this.context, this.context.Request.Body, metaors)
-	} else {
-		metaValues, err = ConvertFormToMetaValues(this.context, this.context.Request, metaors, "QorResource.")
-	}
-	if err != nil {
+	if metaValues, err = requestMetaValues(this.context, result, metaors, "QorResource."); err != nil {
 		return
 	}
 	var f ProcessorFlag
@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v2"
+)
+
+// BodyDecoder decodes request body to the map converted to meta values
+type BodyDecoder interface {
+	DecodeBody(r io.Reader) (map[string]interface{}, error)
+}
+
+// BodyDecoderFunc is a func BodyDecoder
+type BodyDecoderFunc func(r io.Reader) (map[string]interface{}, error)
+
+func (f BodyDecoderFunc) DecodeBody(r io.Reader) (map[string]interface{}, error) {
+	return f(r)
+}
+
+// BodyDecoderRegistry is the registry of body decoders by media type. A key starting with `+` matches the structured
+// syntax suffix of media types, such as `+json` of `application/vnd.api+json`.
+type BodyDecoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]BodyDecoder
+}
+
+// Register registers decoder for media types
+func (this *BodyDecoderRegistry) Register(decoder BodyDecoder, mediaType ...string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.decoders == nil {
+		this.decoders = map[string]BodyDecoder{}
+	}
+	for _, mediaType := range mediaType {
+		this.decoders[strings.ToLower(mediaType)] = decoder
+	}
+}
+
+// Unregister removes the decoders of media types
+func (this *BodyDecoderRegistry) Unregister(mediaType ...string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for _, mediaType := range mediaType {
+		delete(this.decoders, strings.ToLower(mediaType))
+	}
+}
+
+// Get returns the decoder of content type or nil
+func (this *BodyDecoderRegistry) Get(contentType string) BodyDecoder {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	if d, ok := this.decoders[mediaType]; ok {
+		return d
+	}
+	if pos := strings.LastIndexByte(mediaType, '+'); pos > 0 {
+		return this.decoders[mediaType[pos:]]
+	}
+	return nil
+}
+
+// BodyDecoders is the default body decoder registry used by Decode and Decoder.Decode
+var BodyDecoders = &BodyDecoderRegistry{}
+
+// JSONBodyDecoder decodes JSON body
+var JSONBodyDecoder = BodyDecoderFunc(func(r io.Reader) (values map[string]interface{}, err error) {
+	values = map[string]interface{}{}
+	err = json.NewDecoder(r).Decode(&values)
+	return
+})
+
+// UnmarshalBodyDecoder returns a body decoder of unmarshal func. It adapts the decoders of third party formats,
+// such as the default MessagePack and YAML decoders:
+//
+//	resource.BodyDecoders.Register(resource.UnmarshalBodyDecoder(msgpack.Unmarshal), "application/msgpack", "+msgpack")
+//	resource.BodyDecoders.Register(resource.UnmarshalBodyDecoder(yaml.Unmarshal), "application/yaml", "+yaml")
+//
+// Maps with non string keys, as decoded by YAML, are converted to map[string]interface{}. An empty body is decoded
+// to an empty map.
+func UnmarshalBodyDecoder(unmarshal func(data []byte, v interface{}) error) BodyDecoder {
+	return BodyDecoderFunc(func(r io.Reader) (values map[string]interface{}, err error) {
+		var data []byte
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return
+		}
+		values = map[string]interface{}{}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return
+		}
+		var doc interface{}
+		if err = unmarshal(data, &doc); err != nil || doc == nil {
+			return
+		}
+		var ok bool
+		if values, ok = stringKeys(doc).(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("resource: body decoded to %T, expected an object", doc)
+		}
+		return
+	})
+}
+
+// stringKeys converts maps with non string keys to map[string]interface{}
+func stringKeys(value interface{}) interface{} {
+	switch t := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range t {
+			t[k] = stringKeys(v)
+		}
+	case []interface{}:
+		for i, v := range t {
+			t[i] = stringKeys(v)
+		}
+	}
+	return value
+}
+
+func init() {
+	BodyDecoders.Register(JSONBodyDecoder, "application/json", "text/json", "+json")
+	BodyDecoders.Register(UnmarshalBodyDecoder(msgpack.Unmarshal), "application/msgpack", "application/x-msgpack", "+msgpack")
+	BodyDecoders.Register(UnmarshalBodyDecoder(yaml.Unmarshal), "application/yaml", "application/x-yaml", "text/yaml", "+yaml")
+}
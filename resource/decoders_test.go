@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestUnmarshalBodyDecoder(t *testing.T) {
+	yamlLike := func(data []byte, v interface{}) error {
+		*(v.(*interface{})) = map[interface{}]interface{}{
+			"name": string(data),
+			"tags": []interface{}{map[interface{}]interface{}{1: "a"}},
+		}
+		return nil
+	}
+
+	cases := []struct {
+		unmarshal func(data []byte, v interface{}) error
+		body      string
+		want      map[string]interface{}
+		err       bool
+	}{
+		{json.Unmarshal, `{"a": {"b": 1}}`, map[string]interface{}{"a": map[string]interface{}{"b": float64(1)}}, false},
+		{json.Unmarshal, "  ", map[string]interface{}{}, false},
+		{json.Unmarshal, "null", map[string]interface{}{}, false},
+		{json.Unmarshal, "[1]", nil, true},
+		{json.Unmarshal, "{", nil, true},
+		{yamlLike, "x", map[string]interface{}{"name": "x", "tags": []interface{}{map[string]interface{}{"1": "a"}}}, false},
+	}
+
+	for i, c := range cases {
+		got, err := UnmarshalBodyDecoder(c.unmarshal).DecodeBody(strings.NewReader(c.body))
+		if c.err {
+			if err == nil {
+				t.Errorf("#%d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("#%d: expected %v, but got %v", i, c.want, got)
+		}
+	}
+}
+
+func TestBodyDecoderRegistryGet(t *testing.T) {
+	cases := []struct {
+		contentType string
+		found       bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/vnd.api+json", true},
+		{"APPLICATION/JSON", true},
+		{"application/json; charset", false},
+		{"application/x-www-form-urlencoded", false},
+		{"", false},
+	}
+	for i, c := range cases {
+		if got := BodyDecoders.Get(c.contentType) != nil; got != c.found {
+			t.Errorf("#%d: %q: expected found %v, but got %v", i, c.contentType, c.found, got)
+		}
+	}
+}
+
+func TestDefaultBodyDecoders(t *testing.T) {
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"Name": "a", "Items": []interface{}{map[string]interface{}{"Code": "x"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/yaml", []byte("Name: a\nItems:\n  - Code: x\n")},
+		{"application/vnd.api+yaml; charset=utf-8", []byte("Name: a\nItems:\n  - Code: x\n")},
+		{"application/msgpack", msgpackBody},
+		{"application/vnd.api+msgpack", msgpackBody},
+	}
+	want := map[string]interface{}{"Name": "a", "Items": []interface{}{map[string]interface{}{"Code": "x"}}}
+	for _, c := range cases {
+		decoder := BodyDecoders.Get(c.contentType)
+		if decoder == nil {
+			t.Errorf("%s: decoder not registered", c.contentType)
+			continue
+		}
+		got, err := decoder.DecodeBody(bytes.NewReader(c.body))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.contentType, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, but got %v", c.contentType, want, got)
+		}
+	}
+}
+
+func TestRequestMetaValuesBodyDecoders(t *testing.T) {
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"Name": "a", "Note": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string][]byte{
+		"application/yaml":    []byte("Name: a\nNote: b\n"),
+		"application/msgpack": msgpackBody,
+	}
+	for contentType, body := range cases {
+		ctx := core.NewContext()
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		ctx.Request.Header.Set("Content-Type", contentType)
+		values, err := requestMetaValues(ctx, &patchItem{}, patchMetas(), "")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", contentType, err)
+			continue
+		}
+		for name, want := range map[string]string{"Name": "a", "Note": "b"} {
+			if got := values.GetString(name); got != want {
+				t.Errorf("%s: %s: expected %q, but got %q", contentType, name, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/ecletus/core"
+)
+
+// DiffOp is the operation of dry run diff entry
+type DiffOp string
+
+const (
+	// DiffSet is a changed value
+	DiffSet DiffOp = "set"
+	// DiffClear is a value cleared by null patch value
+	DiffClear DiffOp = "clear"
+	// DiffDelete is a nested record marked by `_destroy`
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffEntry is the change of meta path
+type DiffEntry struct {
+	Op   DiffOp      `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+	Meta Metaor      `json:"-"`
+}
+
+// Diff is the structured diff of dry run decoding
+type Diff struct {
+	Entries []*DiffEntry `json:"entries"`
+	// Excludes are the decoder excludes added while decoding
+	Excludes []core.DecoderExclude `json:"excludes,omitempty"`
+	path     []string
+}
+
+// Get returns the entry of path
+func (this *Diff) Get(path string) *DiffEntry {
+	for _, e := range this.Entries {
+		if e.Path == path {
+			return e
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns if has no changes
+func (this *Diff) IsEmpty() bool {
+	return this == nil || (len(this.Entries) == 0 && len(this.Excludes) == 0)
+}
+
+func (this *Diff) enter(name string) func() {
+	this.path = append(this.path, name)
+	return func() {
+		this.path = this.path[:len(this.path)-1]
+	}
+}
+
+func (this *Diff) add(e *DiffEntry, name ...string) {
+	e.Path = strings.Join(append(append([]string{}, this.path...), name...), ".")
+	this.Entries = append(this.Entries, e)
+}
+
+type diffKey struct{}
+
+// DiffOf returns the dry run diff of context, or nil if context is not a dry run
+func DiffOf(ctx *core.Context) *Diff {
+	if d, ok := ctx.Value(diffKey{}).(*Diff); ok {
+		return d
+	}
+	return nil
+}
+
+// IsDryRun returns if context is decoding on dry run. Resource processors do not run on dry run; setters and
+// validators with side effects must check it.
+func IsDryRun(ctx *core.Context) bool {
+	return DiffOf(ctx) != nil
+}
+
+// DecodeDryRun decodes request into result with ProcDryRun flag and returns the diff. The errors of validators do not
+// stop the diff computation.
+func DecodeDryRun(ctx *core.Context, result interface{}, res Resourcer, f ...ProcessorFlag) (diff *Diff, err error) {
+	diff = &Diff{}
+	ctx.SetValue(diffKey{}, diff)
+	defer ctx.DelValue(diffKey{})
+
+	var excludes int
+	if ctx.DecoderExcludes != nil {
+		excludes = len(ctx.DecoderExcludes.Excludes)
+	}
+	err = Decode(ctx, result, res, append(f, ProcDryRun)...)
+	if ctx.DecoderExcludes != nil {
+		diff.Excludes = ctx.DecoderExcludes.Excludes[excludes:]
+	}
+	return
+}
+
+// diffMetaValues adds the changes of scalar meta values between old and new records
+func diffMetaValues(ctx *core.Context, diff *Diff, metaValues *MetaValues, old, new interface{}) {
+	for _, metaValue := range metaValues.Values {
+		meta := metaValue.Meta
+		if meta == nil || meta.GetValuer() == nil {
+			continue
+		}
+		if metaValue.MetaValues != nil {
+			if res := meta.GetResource(); res != nil && !reflect.ValueOf(res).IsNil() {
+				// nested records add their own changes
+				continue
+			}
+		}
+		var (
+			ov = meta.GetValuer()(old, ctx)
+			nv = meta.GetValuer()(new, ctx)
+		)
+		if reflect.DeepEqual(ov, nv) {
+			continue
+		}
+		op := DiffSet
+		if metaValue.Null {
+			op = DiffClear
+		}
+		diff.add(&DiffEntry{Op: op, Old: ov, New: nv, Meta: meta}, meta.GetName())
+	}
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/ecletus/core"
+)
+
+func TestDryRunDiffSnapshot(t *testing.T) {
+	var (
+		ctx    = core.NewContext()
+		diff   = &Diff{}
+		note   = "a"
+		record = &patchItem{Name: "a", Note: &note}
+		metas  = patchMetas()
+		values = &MetaValues{}
+	)
+	for _, meta := range metas {
+		values.Add(&MetaValue{Name: meta.GetName(), Meta: meta})
+	}
+
+	old := Snapshot(record)
+	// setters may change the values through the pointers shared with the old record
+	*record.Note = "b"
+
+	diffMetaValues(ctx, diff, values, old, record)
+	if len(diff.Entries) != 1 {
+		t.Fatalf("expected one entry, but got %v", diff.Entries)
+	}
+	if e := diff.Get("Note"); e == nil || e.Old != "a" || e.New != "b" {
+		t.Errorf("expected Note change from %q to %q, but got %+v", "a", "b", e)
+	}
+}
+
+func TestDryRunProcessor(t *testing.T) {
+	var (
+		res    = New(&tenantItem{}, "", "", nil)
+		called bool
+	)
+	res.AddProcessor(func(record interface{}, metaValues *MetaValues, ctx *core.Context) error {
+		called = true
+		return nil
+	})
+
+	ctx := core.NewContext()
+	p := &Processor{Resource: res, Result: &tenantItem{}, Context: ctx, Flag: ProcDryRun}
+	if p.Diff() != nil {
+		t.Error("expected no diff outside of dry run")
+	}
+	if DiffOf(ctx) != nil {
+		t.Error("expected no diff stored into context")
+	}
+
+	ctx.SetValue(diffKey{}, &Diff{})
+	if err := p.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected processors skipped on dry run")
+	}
+
+	ctx.DelValue(diffKey{})
+	if err := p.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("expected processors called")
+	}
+}
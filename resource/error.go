@@ -28,6 +28,39 @@ func (d DuplicateUniqueIndexError) Cause() error {
 	return d.DuplicateUniqueIndexError
 }
 
+// ConflictError is returned by CRUD.Update when the record was changed by another user since it was loaded
+type ConflictError struct {
+	Resource Resourcer
+	Record   interface{}
+	// Current is the record stored in DB. If the record was deleted, the update returns aorm.ErrRecordNotFound instead.
+	Current interface{}
+}
+
+func (this *ConflictError) Error() string {
+	return fmt.Sprintf("resource %q: record %v was changed by another user", this.Resource.GetID(), this.Resource.GetKey(this.Record))
+}
+
+// IsConflictError returns if err is a ConflictError
+func IsConflictError(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+// BatchRecordError is the error of the record at Index position of a batch action
+type BatchRecordError struct {
+	Index  int
+	Record interface{}
+	Err    error
+}
+
+func (this *BatchRecordError) Error() string {
+	return fmt.Sprintf("record #%d: %v", this.Index, this.Err)
+}
+
+func (this *BatchRecordError) Cause() error {
+	return this.Err
+}
+
 func ErrCantBeBlank(ctx *core.Context, record interface{}, fieldName string, label ...string) error {
 	if len(label) == 0 {
 		label = append(label, utils.HumanizeString(fieldName))
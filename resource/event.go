@@ -45,6 +45,21 @@ func (n DBActionEvent) Names() []string {
 	if (n & E_DB_ACTION_UPDATE) != 0 {
 		s = append(s, "save")
 	}
+	if (n & E_DB_ACTION_CREATE_MANY) != 0 {
+		s = append(s, "createMany")
+	}
+	if (n & E_DB_ACTION_UPDATE_MANY) != 0 {
+		s = append(s, "saveMany")
+	}
+	if (n & E_DB_ACTION_DELETE_MANY) != 0 {
+		s = append(s, "deleteMany")
+	}
+	if (n & E_DB_ACTION_RESTORE) != 0 {
+		s = append(s, "restore")
+	}
+	if (n & E_DB_ACTION_PURGE) != 0 {
+		s = append(s, "purge")
+	}
 	if (n & BEFORE) != 0 {
 		for i := range s {
 			s[i] = "before." + s[i]
@@ -87,6 +102,11 @@ const (
 	E_DB_ACTION_FIND_MANY
 	E_DB_ACTION_FIND_ONE
 	E_DB_ACTION_UPDATE
+	E_DB_ACTION_CREATE_MANY
+	E_DB_ACTION_UPDATE_MANY
+	E_DB_ACTION_DELETE_MANY
+	E_DB_ACTION_RESTORE
+	E_DB_ACTION_PURGE
 )
 
 type DBEvent struct {
@@ -96,6 +116,7 @@ type DBEvent struct {
 	Context *core.Context
 	DBError error
 	old     interface{}
+	records []interface{}
 }
 
 func NewDBEvent(action DBActionEvent, ctx *core.Context) *DBEvent {
@@ -117,6 +138,20 @@ func (e *DBEvent) New() interface{} {
 	return e.Result()
 }
 
+// Changes returns the changed fields of updated record. The changes are available only if the old record was
+// given to CRUD.Update or was loaded by Processor before decoding.
+func (e *DBEvent) Changes() MetaChanges {
+	if e.old == nil || e.Result() == nil {
+		return nil
+	}
+	return RecordChanges(e.Crud.res, e.old, e.Result())
+}
+
+// Records returns the records of batch actions
+func (e *DBEvent) Records() []interface{} {
+	return e.records
+}
+
 func (e *DBEvent) Resource() Resourcer {
 	return e.Crud.res
 }
@@ -0,0 +1,69 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/ecletus/core/resource"
+)
+
+// CSVWriter writes rows as CSV with header
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter creates a new CSV writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{csv.NewWriter(w)}
+}
+
+func (this *CSVWriter) WriteHeader(names []string) error {
+	return this.w.Write(names)
+}
+
+func (this *CSVWriter) WriteRow(values []*resource.FormattedValue) error {
+	record := make([]string, len(values))
+	for i, fv := range values {
+		record[i] = Text(fv)
+	}
+	return this.w.Write(record)
+}
+
+func (this *CSVWriter) Flush() error {
+	this.w.Flush()
+	return this.w.Error()
+}
+
+// CSVReader reads rows of CSV. The first row is the header of meta names.
+type CSVReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader creates a new CSV reader
+func NewCSVReader(r io.Reader) *CSVReader {
+	return &CSVReader{r: csv.NewReader(r)}
+}
+
+func (this *CSVReader) Read() (row map[string]interface{}, err error) {
+	if this.header == nil {
+		if this.header, err = this.r.Read(); err != nil {
+			return
+		}
+	}
+	var record []string
+	if record, err = this.r.Read(); err != nil {
+		return
+	}
+	return rowOf(this.header, record), nil
+}
+
+func rowOf(header, record []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(header))
+	for i, name := range header {
+		if i < len(record) {
+			row[name] = record[i]
+		}
+	}
+	return row
+}
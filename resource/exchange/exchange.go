@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/roles"
+
+	"github.com/ecletus/core/resource"
+)
+
+// SliceSeparator is the separator of slice values on text formats
+var SliceSeparator = "; "
+
+// Writer writes exported rows
+type Writer interface {
+	// WriteHeader writes the meta names. It is called once, before rows.
+	WriteHeader(names []string) error
+	WriteRow(values []*resource.FormattedValue) error
+	// Flush writes the buffered data
+	Flush() error
+}
+
+// Reader reads imported rows by meta name. Returns io.EOF after last row.
+type Reader interface {
+	Read() (row map[string]interface{}, err error)
+}
+
+// ReadableMetas returns the context metas of res without read permission denied
+func ReadableMetas(ctx *core.Context, res resource.Resourcer) (metas []resource.Metaor) {
+	for _, meta := range res.GetContextMetas(ctx) {
+		if meta.HasPermission(roles.Read, ctx) != roles.DENY {
+			metas = append(metas, meta)
+		}
+	}
+	return
+}
+
+// Text returns the text of formatted value
+func Text(fv *resource.FormattedValue) string {
+	if fv == nil {
+		return ""
+	}
+	if fv.Slice {
+		if len(fv.Values) > 0 {
+			return strings.Join(fv.Values, SliceSeparator)
+		}
+		if fv.Raws == nil {
+			return ""
+		}
+		var (
+			raws  = reflect.Indirect(reflect.ValueOf(fv.Raws))
+			parts []string
+		)
+		for i := 0; i < raws.Len(); i++ {
+			parts = append(parts, fmt.Sprint(raws.Index(i).Interface()))
+		}
+		return strings.Join(parts, SliceSeparator)
+	}
+	if fv.Value != "" || fv.Raw == nil {
+		return fv.Value
+	}
+	raw := reflect.ValueOf(fv.Raw)
+	if raw.Kind() == reflect.Ptr {
+		if raw.IsNil() {
+			return ""
+		}
+		raw = raw.Elem()
+	}
+	return fmt.Sprint(raw.Interface())
+}
+
+// Value returns the JSON value of formatted value
+func Value(fv *resource.FormattedValue) interface{} {
+	if fv == nil {
+		return nil
+	}
+	if fv.Slice {
+		if len(fv.Values) > 0 {
+			return fv.Values
+		}
+		return fv.Raws
+	}
+	if fv.Value != "" {
+		return fv.Value
+	}
+	return fv.Raw
+}
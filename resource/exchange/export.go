@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/ecletus/core/resource"
+)
+
+// Export streams the records found by crud into w, formatted by metas. If metas is empty, uses the readable
+// context metas of crud resource.
+func Export(ctx context.Context, crud *resource.CRUD, w Writer, metas ...resource.Metaor) (err error) {
+	if len(metas) == 0 {
+		metas = ReadableMetas(crud.Context(), crud.Resource())
+	}
+
+	names := make([]string, len(metas))
+	for i, meta := range metas {
+		names[i] = meta.GetName()
+	}
+	if err = w.WriteHeader(names); err != nil {
+		return
+	}
+
+	coreCtx := crud.Context()
+	if err = crud.Stream(ctx, func(record interface{}) error {
+		values := make([]*resource.FormattedValue, len(metas))
+		for i, meta := range metas {
+			values[i] = meta.GetFormattedValuer()(record, coreCtx)
+		}
+		return w.WriteRow(values)
+	}); err != nil {
+		return
+	}
+	return w.Flush()
+}
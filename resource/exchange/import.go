@@ -0,0 +1,135 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+)
+
+// RowError is the import error of row. Row starts at 1.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (this *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", this.Row, this.Err)
+}
+
+func (this *RowError) Cause() error {
+	return this.Err
+}
+
+// Report is the import result. On dry run, Created and Updated are the counts of valid rows.
+type Report struct {
+	DryRun  bool
+	Rows    int
+	Created int
+	Updated int
+	Errors  []*RowError
+}
+
+// HasError returns if any row fails
+func (this *Report) HasError() bool {
+	return len(this.Errors) > 0
+}
+
+// Importer decodes rows into records of resource using the processor, so validators, processors and permissions
+// are applied, then saves it. Each row is saved into a savepoint of a transaction; a failed row does not stop the
+// import. On dry run mode, rows are decoded with the ProcDryRun flag and validated only: processors do not run and
+// records are not saved.
+type Importer struct {
+	Resource resource.Resourcer
+	Metas    []resource.Metaor
+	DryRun   bool
+}
+
+// NewImporter creates a new importer of res
+func NewImporter(res resource.Resourcer) *Importer {
+	return &Importer{Resource: res}
+}
+
+// Import reads all rows of r and imports it
+func (this *Importer) Import(ctx *core.Context, r Reader) (report *Report, err error) {
+	var (
+		metas = this.Metas
+		crud  = this.Resource.Crud(ctx)
+	)
+	if len(metas) == 0 {
+		metas = this.Resource.GetContextMetas(ctx)
+	}
+
+	report = &Report{DryRun: this.DryRun}
+
+	if this.DryRun {
+		err = this.importRows(crud, metas, r, report)
+		return
+	}
+	err = crud.Transaction(func(tx *resource.CRUD) error {
+		return this.importRows(tx, metas, r, report)
+	})
+	return
+}
+
+func (this *Importer) importRows(crud *resource.CRUD, metas []resource.Metaor, r Reader, report *Report) (err error) {
+	for {
+		var row map[string]interface{}
+		if row, err = r.Read(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return
+		}
+		report.Rows++
+
+		var (
+			created   bool
+			rowErr    error
+			importRow = func(tx *resource.CRUD) (err error) {
+				created, err = this.importRow(tx, metas, row)
+				return
+			}
+		)
+		if this.DryRun {
+			rowErr = importRow(crud)
+		} else {
+			rowErr = crud.Transaction(importRow)
+		}
+
+		if rowErr != nil {
+			report.Errors = append(report.Errors, &RowError{report.Rows, rowErr})
+		} else if created {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+}
+
+func (this *Importer) importRow(crud *resource.CRUD, metas []resource.Metaor, row map[string]interface{}) (created bool, err error) {
+	var (
+		ctx        = crud.Context()
+		record     = this.Resource.NewStruct(ctx.Site)
+		metaValues *resource.MetaValues
+		flags      []resource.ProcessorFlag
+	)
+	if this.DryRun {
+		flags = append(flags, resource.ProcDryRun)
+	}
+	if metaValues, err = resource.ConvertMapToMetaValues(ctx, row, metas); err != nil {
+		return
+	}
+	if err = resource.DecodeMetaValues(ctx, record, this.Resource, "", metaValues, flags...); err != nil {
+		return
+	}
+	key := this.Resource.GetKey(record)
+	created = key == nil || key.IsZero()
+	if this.DryRun {
+		return
+	}
+	if created {
+		return true, crud.Create(record)
+	}
+	return false, crud.Update(record)
+}
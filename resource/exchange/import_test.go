@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+)
+
+type importItem struct {
+	ID   int64 `aorm:"primary_key"`
+	Name string
+}
+
+type rowsReader []map[string]interface{}
+
+func (this *rowsReader) Read() (row map[string]interface{}, err error) {
+	if len(*this) == 0 {
+		return nil, io.EOF
+	}
+	row, *this = (*this)[0], (*this)[1:]
+	return
+}
+
+func TestImportDryRun(t *testing.T) {
+	var (
+		res       = resource.New(&importItem{}, "", "", nil)
+		processed bool
+	)
+	res.SetRepository(resource.NewMemoryRepository())
+	res.AddValidator(func(record interface{}, metaValues *resource.MetaValues, ctx *core.Context) error {
+		if record.(*importItem).Name == "" {
+			return errors.New("blank name")
+		}
+		return nil
+	})
+	res.AddProcessor(func(record interface{}, metaValues *resource.MetaValues, ctx *core.Context) error {
+		processed = true
+		return nil
+	})
+
+	importer := NewImporter(res)
+	importer.DryRun = true
+	importer.Metas = []resource.Metaor{&resource.Meta{MetaName: &resource.MetaName{Name: "Name"}, FieldName: "Name",
+		Setter: func(record interface{}, metaValue *resource.MetaValue, ctx *core.Context) error {
+			record.(*importItem).Name = metaValue.StringValue()
+			return nil
+		}}}
+
+	report, err := importer.Import(core.NewContext(), &rowsReader{{"Name": "a"}, {"Name": ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Rows != 2 || report.Created != 1 || len(report.Errors) != 1 || report.Errors[0].Row != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if processed {
+		t.Errorf("processors called on dry run")
+	}
+
+	var count int
+	if err = res.Crud(core.NewContext()).Count(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected no saved records, but got %d", count)
+	}
+}
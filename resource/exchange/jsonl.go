@@ -0,0 +1,51 @@
+package exchange
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ecletus/core/resource"
+)
+
+// JSONLinesWriter writes rows as JSON objects, one per line
+type JSONLinesWriter struct {
+	enc   *json.Encoder
+	names []string
+}
+
+// NewJSONLinesWriter creates a new JSON Lines writer
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	return &JSONLinesWriter{enc: json.NewEncoder(w)}
+}
+
+func (this *JSONLinesWriter) WriteHeader(names []string) error {
+	this.names = names
+	return nil
+}
+
+func (this *JSONLinesWriter) WriteRow(values []*resource.FormattedValue) error {
+	row := make(map[string]interface{}, len(values))
+	for i, fv := range values {
+		row[this.names[i]] = Value(fv)
+	}
+	return this.enc.Encode(row)
+}
+
+func (this *JSONLinesWriter) Flush() error {
+	return nil
+}
+
+// JSONLinesReader reads rows of JSON objects, one per line
+type JSONLinesReader struct {
+	dec *json.Decoder
+}
+
+// NewJSONLinesReader creates a new JSON Lines reader
+func NewJSONLinesReader(r io.Reader) *JSONLinesReader {
+	return &JSONLinesReader{json.NewDecoder(r)}
+}
+
+func (this *JSONLinesReader) Read() (row map[string]interface{}, err error) {
+	err = this.dec.Decode(&row)
+	return
+}
@@ -0,0 +1,62 @@
+package exchange
+
+import "github.com/ecletus/core/resource"
+
+// SheetWriter writes rows as cells of a spreadsheet, such as the XLSX writer (see NewXLSXWriter)
+type SheetWriter struct {
+	writeRow func(cells []interface{}) error
+	flush    func() error
+}
+
+// NewSheetWriter creates a new sheet writer. The header is the first row.
+func NewSheetWriter(writeRow func(cells []interface{}) error, flush func() error) *SheetWriter {
+	return &SheetWriter{writeRow, flush}
+}
+
+func (this *SheetWriter) WriteHeader(names []string) error {
+	cells := make([]interface{}, len(names))
+	for i, name := range names {
+		cells[i] = name
+	}
+	return this.writeRow(cells)
+}
+
+func (this *SheetWriter) WriteRow(values []*resource.FormattedValue) error {
+	cells := make([]interface{}, len(values))
+	for i, fv := range values {
+		cells[i] = Text(fv)
+	}
+	return this.writeRow(cells)
+}
+
+func (this *SheetWriter) Flush() error {
+	if this.flush == nil {
+		return nil
+	}
+	return this.flush()
+}
+
+// SheetReader reads rows of a spreadsheet, such as the XLSX reader (see NewXLSXReader). The first row is the
+// header of meta names. The next func returns io.EOF after last row.
+type SheetReader struct {
+	next   func() ([]string, error)
+	header []string
+}
+
+// NewSheetReader creates a new sheet reader
+func NewSheetReader(next func() ([]string, error)) *SheetReader {
+	return &SheetReader{next: next}
+}
+
+func (this *SheetReader) Read() (row map[string]interface{}, err error) {
+	if this.header == nil {
+		if this.header, err = this.next(); err != nil {
+			return
+		}
+	}
+	var record []string
+	if record, err = this.next(); err != nil {
+		return
+	}
+	return rowOf(this.header, record), nil
+}
@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ecletus/core/resource"
+)
+
+func TestSheetWriter(t *testing.T) {
+	var (
+		rows    [][]interface{}
+		flushed bool
+		w       = NewSheetWriter(func(cells []interface{}) error {
+			rows = append(rows, cells)
+			return nil
+		}, func() error {
+			flushed = true
+			return nil
+		})
+	)
+	if err := w.WriteHeader([]string{"Name", "Tags"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]*resource.FormattedValue{{Value: "a"}, {Slice: true, Values: []string{"x", "y"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]interface{}{{"Name", "Tags"}, {"a", "x" + SliceSeparator + "y"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected rows %v, but got %v", want, rows)
+	}
+	if !flushed {
+		t.Errorf("flush not called")
+	}
+}
+
+func TestSheetReader(t *testing.T) {
+	var (
+		records = [][]string{{"Name", "Code"}, {"a", "1"}, {"b"}}
+		r       = NewSheetReader(func() (record []string, err error) {
+			if len(records) == 0 {
+				return nil, io.EOF
+			}
+			record, records = records[0], records[1:]
+			return
+		})
+		want = []map[string]interface{}{{"Name": "a", "Code": "1"}, {"Name": "b"}}
+	)
+	for i, w := range want {
+		row, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(row, w) {
+			t.Errorf("#%d: expected %v, but got %v", i, w, row)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected io.EOF, but got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package exchange
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// NewXLSXWriter creates a new sheet writer of the first sheet of a XLSX file using the excelize stream writer.
+// The file is written to w on Flush.
+func NewXLSXWriter(w io.Writer) (_ *SheetWriter, err error) {
+	var (
+		file   = excelize.NewFile()
+		stream *excelize.StreamWriter
+		row    int
+	)
+	if stream, err = file.NewStreamWriter(file.GetSheetName(0)); err != nil {
+		return
+	}
+	return NewSheetWriter(func(cells []interface{}) (err error) {
+		row++
+		var cell string
+		if cell, err = excelize.CoordinatesToCellName(1, row); err != nil {
+			return
+		}
+		return stream.SetRow(cell, cells)
+	}, func() (err error) {
+		if err = stream.Flush(); err != nil {
+			return
+		}
+		return file.Write(w)
+	}), nil
+}
+
+// NewXLSXReader creates a new sheet reader of the first sheet of a XLSX file. The first row is the header of meta
+// names.
+func NewXLSXReader(r io.Reader) (_ *SheetReader, err error) {
+	var (
+		file *excelize.File
+		rows *excelize.Rows
+	)
+	if file, err = excelize.OpenReader(r); err != nil {
+		return
+	}
+	if rows, err = file.Rows(file.GetSheetName(0)); err != nil {
+		return
+	}
+	return NewSheetReader(func() (record []string, err error) {
+		if !rows.Next() {
+			if err = rows.Error(); err == nil {
+				err = io.EOF
+			}
+			return
+		}
+		return rows.Columns()
+	}), nil
+}
@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ecletus/core/resource"
+)
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.WriteHeader([]string{"Name", "Tags"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range [][]*resource.FormattedValue{
+		{{Value: "a"}, {Slice: true, Values: []string{"x", "y"}}},
+		{{Value: "b"}, nil},
+	} {
+		if err = w.WriteRow(values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewXLSXReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]interface{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	// trailing empty cells are not read
+	want := []map[string]interface{}{{"Name": "a", "Tags": "x" + SliceSeparator + "y"}, {"Name": "b"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected rows %v, but got %v", want, rows)
+	}
+}
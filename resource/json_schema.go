@@ -0,0 +1,240 @@
+package resource
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/utils"
+	"github.com/ecletus/roles"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of generated schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Enumer is implemented by field types with enumerated values
+type Enumer interface {
+	EnumValues() []interface{}
+}
+
+// JSONSchema is the JSON Schema draft 2020-12 document
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+}
+
+var (
+	jsonSchemaTimeType   = reflect.TypeOf(time.Time{})
+	jsonSchemaEnumerType = reflect.TypeOf((*Enumer)(nil)).Elem()
+	jsonSchemaZero       = float64(0)
+	jsonSchemaOne        = 1
+)
+
+// JSONSchema returns the JSON Schema of resource form. See ResourceJSONSchema.
+func (res *Resource) JSONSchema(ctx *core.Context, layout string) *JSONSchema {
+	return ResourceJSONSchema(ctx, res, layout)
+}
+
+// ResourceJSONSchema returns the JSON Schema of resource form, with properties named by meta name. Metas without
+// read permission are left out and metas without update permission are read only. Titles are the meta labels and
+// descriptions the translated meta help. Inline metas of resources are sub schemas into `$defs`. If the layout
+// type is not the resource model, the schema describes the layout type.
+func ResourceJSONSchema(ctx *core.Context, res Resourcer, layout string) (schema *JSONSchema) {
+	defs := map[string]*JSONSchema{}
+	if l := res.GetLayout(layout); l != nil && l.GetType() != nil {
+		if typ := utils.IndirectType(reflect.TypeOf(l.GetType())); typ != res.GetModelStruct().Type {
+			schema = TypeJSONSchema(typ)
+		}
+	}
+	if schema == nil {
+		schema = resourceJSONSchema(ctx, res, defs)
+	}
+	schema.Schema = JSONSchemaDraft
+	if len(defs) > 0 {
+		schema.Defs = defs
+	}
+	return
+}
+
+func resourceJSONSchema(ctx *core.Context, res Resourcer, defs map[string]*JSONSchema) *JSONSchema {
+	if _, ok := res.(*Resource); ok {
+		// base resource does not have metas
+		return TypeJSONSchema(res.GetModelStruct().Type)
+	}
+
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+	for _, meta := range res.GetContextMetas(ctx) {
+		if meta.HasPermission(roles.Read, ctx) == roles.DENY {
+			continue
+		}
+		prop := metaJSONSchema(ctx, res, meta, defs)
+		if meta.GetSetter() == nil || meta.HasPermission(roles.Update, ctx) == roles.DENY {
+			prop.ReadOnly = true
+		} else if meta.IsRequired() {
+			schema.Required = append(schema.Required, meta.GetName())
+			if prop.Type == "string" {
+				prop.MinLength = &jsonSchemaOne
+			}
+		}
+		schema.Properties[meta.GetName()] = prop
+	}
+	return schema
+}
+
+func metaJSONSchema(ctx *core.Context, res Resourcer, meta Metaor, defs map[string]*JSONSchema) (schema *JSONSchema) {
+	if metaRes := meta.GetResource(); meta.IsInline() && metaRes != nil {
+		id := metaRes.GetResource().ID
+		if _, ok := defs[id]; !ok {
+			// reserve the definition to stop recursion
+			defs[id] = &JSONSchema{}
+			*defs[id] = *resourceJSONSchema(ctx, metaRes, defs)
+		}
+		schema = &JSONSchema{Ref: "#/$defs/" + id}
+		if meta.CanCollection() {
+			schema = &JSONSchema{Type: "array", Items: schema}
+		}
+		describeMetaJSONSchema(ctx, res, meta, schema)
+		return
+	}
+	return MetaJSONSchema(ctx, meta)
+}
+
+// MetaJSONSchema returns the JSON Schema of meta value type, titled by meta label and described by translated meta
+// help.
+func MetaJSONSchema(ctx *core.Context, meta Metaor) (schema *JSONSchema) {
+	var typ reflect.Type
+	if m, ok := meta.(*Meta); ok && m.Typ != nil {
+		typ = m.Typ
+	} else if field := meta.GetFieldStruct(); field != nil {
+		typ = field.Struct.Type
+	}
+	if typ == nil {
+		schema = &JSONSchema{}
+	} else {
+		schema = TypeJSONSchema(typ)
+	}
+	describeMetaJSONSchema(ctx, meta.GetBaseResource(), meta, schema)
+	return
+}
+
+func describeMetaJSONSchema(ctx *core.Context, res Resourcer, meta Metaor, schema *JSONSchema) {
+	schema.Title = meta.GetLabelC(ctx)
+	if m, ok := meta.(*Meta); ok {
+		schema.Description = m.Help
+		if res != nil && (ctx.Translator != nil || ctx.I18nContext != nil) {
+			schema.Description = ctx.Ts(res.GetResource().I18nPrefix+".metas."+meta.GetName()+".help", m.Help)
+		}
+	}
+}
+
+// TypeJSONSchema returns the JSON Schema of type. Structs are described by exported fields, using json tag names.
+// Integer and float sizes are annotated by the `int32`, `int64`, `float` and `double` formats.
+func TypeJSONSchema(typ reflect.Type) *JSONSchema {
+	return typeJSONSchema(typ, map[reflect.Type]bool{})
+}
+
+func typeJSONSchema(typ reflect.Type, visited map[reflect.Type]bool) (schema *JSONSchema) {
+	if typ.Kind() == reflect.Ptr {
+		schema = typeJSONSchema(typ.Elem(), visited)
+		if t, ok := schema.Type.(string); ok {
+			schema.Type = []string{t, "null"}
+		}
+		return
+	}
+
+	schema = &JSONSchema{}
+	if typ.Implements(jsonSchemaEnumerType) {
+		schema.Enum = reflect.Zero(typ).Interface().(Enumer).EnumValues()
+	} else if reflect.PtrTo(typ).Implements(jsonSchemaEnumerType) {
+		schema.Enum = reflect.New(typ).Interface().(Enumer).EnumValues()
+	}
+
+	if typ == jsonSchemaTimeType {
+		schema.Type, schema.Format = "string", "date-time"
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		schema.Type, schema.Format = "integer", "int32"
+	case reflect.Int, reflect.Int64:
+		schema.Type, schema.Format = "integer", "int64"
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		schema.Type, schema.Format, schema.Minimum = "integer", "int32", &jsonSchemaZero
+	case reflect.Uint, reflect.Uint64:
+		schema.Type, schema.Format, schema.Minimum = "integer", "int64", &jsonSchemaZero
+	case reflect.Float32:
+		schema.Type, schema.Format = "number", "float"
+	case reflect.Float64:
+		schema.Type, schema.Format = "number", "double"
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			schema.Type, schema.ContentEncoding = "string", "base64"
+		} else {
+			schema.Type, schema.Items = "array", typeJSONSchema(typ.Elem(), visited)
+		}
+	case reflect.Map:
+		schema.Type, schema.AdditionalProperties = "object", typeJSONSchema(typ.Elem(), visited)
+	case reflect.Struct:
+		schema.Type = "object"
+		if visited[typ] {
+			return
+		}
+		visited[typ] = true
+		defer delete(visited, typ)
+		schema.Properties = map[string]*JSONSchema{}
+		structJSONSchemaProperties(typ, schema, visited)
+	}
+	return
+}
+
+func structJSONSchemaProperties(typ reflect.Type, schema *JSONSchema, visited map[reflect.Type]bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, omit := JSONFieldName(field)
+		if omit {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			if t := utils.IndirectType(field.Type); t.Kind() == reflect.Struct {
+				structJSONSchemaProperties(t, schema, visited)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = typeJSONSchema(field.Type, visited)
+	}
+}
+
+// JSONFieldName returns the json tag name of field. Omit is true if the field is ignored by `json:"-"`.
+func JSONFieldName(field reflect.StructField) (name string, omit bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
@@ -20,6 +20,7 @@ type Layout struct {
 	PrepareFunc      func(crud *CRUD) *CRUD
 	FormatResultFunc func(crud *CRUD, result interface{}) interface{}
 	selects          []interface{}
+	preloads         []string
 }
 
 func (l *Layout) GetType() interface{} {
@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/utils"
+	"github.com/go-aorm/aorm"
+)
+
+// LayoutPreloader is implemented by layouts that declares the relation fields loaded in batch by FindManyLayout
+type LayoutPreloader interface {
+	GetPreloads() []string
+}
+
+// Preload declares the relation fields loaded in batch by FindManyLayout, one query per relation
+func (l *Layout) Preload(fieldNames ...string) {
+	l.preloads = append(l.preloads, fieldNames...)
+}
+
+// GetPreloads returns the relation fields loaded in batch
+func (l *Layout) GetPreloads() []string {
+	return l.preloads
+}
+
+// preload loads the relations of records of slice, one `WHERE key IN (...)` query per relation. The relations
+// are found by CRUD of the relation meta resource, so its DB events (like tenant and soft delete scopes) apply.
+func (this *CRUD) preload(slice interface{}, fieldNames []string) (err error) {
+	records := reflect.Indirect(reflect.ValueOf(slice))
+	if records.Len() == 0 {
+		return
+	}
+	modelStruct := this.res.GetModelStruct()
+	for _, fieldName := range fieldNames {
+		field, ok := modelStruct.FieldsByName[fieldName]
+		if !ok || field.Relationship == nil {
+			return fmt.Errorf("resource %q: preload: %q is not a relation", this.res.GetResource().UID, fieldName)
+		}
+		if err = this.preloadRelation(records, field); err != nil {
+			return fmt.Errorf("resource %q: preload %q: %v", this.res.GetResource().UID, fieldName, err)
+		}
+	}
+	return
+}
+
+func (this *CRUD) preloadRelation(records reflect.Value, field *aorm.StructField) (err error) {
+	var (
+		rel         = field.Relationship
+		modelStruct = this.res.GetModelStruct()
+		srcName     string
+		dstName     string
+		dstDBName   string
+	)
+
+	if len(rel.ForeignFieldNames) != 1 || len(rel.AssociationForeignFieldNames) != 1 {
+		return fmt.Errorf("composite keys is not supported")
+	}
+
+	switch rel.Kind {
+	case aorm.BELONGS_TO:
+		srcName, dstName, dstDBName = rel.ForeignFieldNames[0], rel.AssociationForeignFieldNames[0], rel.AssociationForeignDBNames[0]
+	case aorm.HAS_ONE, aorm.HAS_MANY:
+		srcName, dstName, dstDBName = rel.AssociationForeignFieldNames[0], rel.ForeignFieldNames[0], rel.ForeignDBNames[0]
+	default:
+		return fmt.Errorf("relation kind %q is not supported", rel.Kind)
+	}
+
+	srcField, ok := modelStruct.FieldsByName[srcName]
+	if !ok {
+		return fmt.Errorf("key field %q does not exists", srcName)
+	}
+
+	var (
+		keys    []interface{}
+		keysSet = map[string]bool{}
+		keyOf   = func(v reflect.Value) (string, bool) {
+			if v = reflect.Indirect(v); !v.IsValid() || v.IsZero() {
+				return "", false
+			}
+			return fmt.Sprint(v.Interface()), true
+		}
+	)
+
+	for i := 0; i < records.Len(); i++ {
+		v := reflect.Indirect(records.Index(i)).FieldByIndex(srcField.StructIndex)
+		if key, ok := keyOf(v); ok && !keysSet[key] {
+			keysSet[key] = true
+			keys = append(keys, reflect.Indirect(v).Interface())
+		}
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	elemType := field.Struct.Type
+	if elemType.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+	}
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	var (
+		related = reflect.New(reflect.SliceOf(reflect.PtrTo(elemType)))
+		ctx     = this.context.Clone()
+		// the conditions of this resource don't apply to relation, but the DB settings (like tenant) do
+		DB    = ctx.DB().New()
+		scope = DB.NewScope(related.Interface())
+		byKey = map[string][]reflect.Value{}
+	)
+
+	DB = DB.Where(fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(dstDBName)), keys)
+
+	if res := this.preloadResource(field.Name, elemType); res != nil {
+		ctx.SetRawDB(DB)
+		err = NewCrud(res, ctx).FindMany(related.Interface())
+	} else {
+		if DB, err = this.preloadTenantDB(ctx, DB, scope, elemType); err != nil {
+			return
+		}
+		err = DB.Find(related.Interface()).Error
+	}
+	if err != nil {
+		return
+	}
+
+	for i, l := 0, related.Elem().Len(); i < l; i++ {
+		ptr := related.Elem().Index(i)
+		if key, ok := keyOf(ptr.Elem().FieldByName(dstName)); ok {
+			byKey[key] = append(byKey[key], ptr)
+		}
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		var (
+			record = reflect.Indirect(records.Index(i))
+			key, _ = keyOf(record.FieldByIndex(srcField.StructIndex))
+			values = byKey[key]
+			target = record.FieldByIndex(field.StructIndex)
+		)
+		if len(values) == 0 {
+			continue
+		}
+		if target.Kind() == reflect.Slice {
+			s := reflect.MakeSlice(target.Type(), 0, len(values))
+			for _, ptr := range values {
+				if target.Type().Elem().Kind() == reflect.Ptr {
+					s = reflect.Append(s, ptr)
+				} else {
+					s = reflect.Append(s, ptr.Elem())
+				}
+			}
+			target.Set(s)
+		} else if target.Kind() == reflect.Ptr {
+			target.Set(values[0])
+		} else {
+			target.Set(values[0].Elem())
+		}
+	}
+	return
+}
+
+// preloadResource returns the resource of relation meta if its model type is elemType
+func (this *CRUD) preloadResource(fieldName string, elemType reflect.Type) Resourcer {
+	if _, ok := this.res.(*Resource); ok {
+		// base resource does not have metas
+		return nil
+	}
+	metas := this.res.GetMetas([]string{fieldName})
+	if len(metas) != 1 {
+		return nil
+	}
+	res := metas[0].GetResource()
+	if res == nil || reflect.ValueOf(res).IsNil() || utils.IndirectType(reflect.TypeOf(res.GetValue())) != elemType {
+		return nil
+	}
+	return res
+}
+
+// preloadTenantDB returns DB with tenant condition of this resource if relation model has the tenant field
+func (this *CRUD) preloadTenantDB(ctx *core.Context, DB *aorm.DB, scope *aorm.Scope, elemType reflect.Type) (_ *aorm.DB, err error) {
+	res := this.res.GetResource()
+	if res.TenantScope == nil {
+		return DB, nil
+	}
+	field, ok := aorm.StructOf(reflect.New(elemType).Interface()).FieldsByName[res.TenantScope.FieldName]
+	if !ok {
+		return DB, nil
+	}
+	var tenantID string
+	if tenantID, err = res.tenantID(ctx); err != nil || tenantID == "" {
+		return DB, err
+	}
+	return DB.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)), tenantID), nil
+}
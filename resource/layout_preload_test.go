@@ -0,0 +1,47 @@
+package resource
+
+import (
+	"testing"
+)
+
+type preloadCategory struct {
+	ID     int64  `aorm:"primary_key"`
+	SiteID string `aorm:"size:64"`
+	Name   string `aorm:"size:64"`
+}
+
+type preloadItem struct {
+	ID         int64  `aorm:"primary_key"`
+	SiteID     string `aorm:"size:64"`
+	CategoryID int64
+	Category   *preloadCategory
+}
+
+func TestPreloadTenantScope(t *testing.T) {
+	db := testDB(t, &preloadCategory{}, &preloadItem{})
+	res := New(&preloadItem{}, "", "", nil)
+	if err := res.SetTenantScoped(); err != nil {
+		t.Fatal(err)
+	}
+
+	own, other := &preloadCategory{SiteID: "a", Name: "own"}, &preloadCategory{SiteID: "b", Name: "other"}
+	for _, c := range []*preloadCategory{own, other} {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := []*preloadItem{
+		{SiteID: "a", CategoryID: own.ID},
+		{SiteID: "a", CategoryID: other.ID},
+	}
+	if err := tenantCrud(res, db, "a").preload(&items, []string{"Category"}); err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Category == nil || items[0].Category.Name != "own" {
+		t.Errorf("expected own category, but got %+v", items[0].Category)
+	}
+	if items[1].Category != nil {
+		t.Errorf("category of other tenant loaded: %+v", items[1].Category)
+	}
+}
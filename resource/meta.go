@@ -73,6 +73,8 @@ type Meta struct {
 	Required                   bool
 	Icon                       bool
 	validators                 []func(record interface{}, values *MetaValue, ctx *core.Context) (err error)
+	validationRules            []*ValidationRule
+	asyncValidators            []*AsyncValidator
 	Data                       maps.Map
 	Typ                        reflect.Type
 	UIValidatorFunc            func(ctx *core.Context, recorde interface{}) string
@@ -133,7 +135,7 @@ func (this *Meta) UIValidator(ctx *core.Context, recorde interface{}) string {
 	if this.UIValidatorFunc != nil {
 		return this.UIValidatorFunc(ctx, recorde)
 	}
-	return ""
+	return ValidationRulesUIAttrs(this.validationRules)
 }
 
 func (this *Meta) Validators() []func(record interface{}, values *MetaValue, ctx *core.Context) (err error) {
@@ -309,6 +311,15 @@ func (this *Meta) PreInitialize() error {
 			this.Typ = this.FieldStruct.Struct.Type
 		}
 	}
+	if this.FieldStruct != nil {
+		if tag := this.FieldStruct.Struct.Tag.Get(ValidationTag); tag != "" {
+			rules, err := ParseValidationRules(tag)
+			if err != nil {
+				return fmt.Errorf("meta %q: %v", this.Name, err)
+			}
+			this.validationRules = append(rules, this.validationRules...)
+		}
+	}
 	return nil
 }
 
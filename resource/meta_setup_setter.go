@@ -38,6 +38,12 @@ func Setter(newValue GetFielder, setter func(ptr bool, value reflect.Value, meta
 			if value, err = newValue(context, record, metaValue); err != nil {
 				return
 			}
+			if metaValue.Null {
+				if value.CanSet() {
+					value.Set(reflect.Zero(value.Type()))
+				}
+				return nil
+			}
 			ptr := value.Kind() == reflect.Ptr
 			if ptr {
 				if utils.ToString(metaValue.Value) == "" {
@@ -78,6 +84,18 @@ func GenericSetter(meta Metaor, fieldName string, setter func(field reflect.Valu
 		}()
 
 		field := reflect.Indirect(reflect.ValueOf(record)).FieldByName(fieldName)
+		if metaValue.Null {
+			// explicitly cleared: clear the field and the foreign keys of belongs to relationship
+			if fieldStruct := meta.GetFieldStruct(); fieldStruct != nil && fieldStruct.Relationship != nil &&
+				fieldStruct.Relationship.Kind == aorm.BELONGS_TO {
+				for _, name := range fieldStruct.Relationship.ForeignFieldNames {
+					fk := reflect.Indirect(reflect.ValueOf(record)).FieldByName(name)
+					fk.Set(reflect.Zero(fk.Type()))
+				}
+			}
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
 		if field.Kind() == reflect.Ptr {
 			if utils.ToString(metaValue.Value) != "" {
 				if fieldStruct := metaValue.Meta.GetFieldStruct(); field.Type().Elem().Kind() == reflect.Struct &&
@@ -194,7 +212,7 @@ func setupSetter(meta *Meta, fieldName string, record interface{}) {
 
 					// Replace many 2 many relations
 					if relationship.Kind == aorm.M2M {
-						if !aorm.ZeroIdOf(record) {
+						if !aorm.ZeroIdOf(record) && !IsDryRun(context) {
 							context.DB().Model(record).Association(meta.FieldName).Replace(field.Interface())
 							field.Set(reflect.Zero(field.Type()))
 						}
@@ -3,6 +3,7 @@ package resource
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/jinzhu/copier"
@@ -17,6 +18,7 @@ import (
 // MetaValues is slice of MetaValue
 type MetaValues struct {
 	Disabled bool
+	Patch    bool
 	Values   []*MetaValue
 	ByName   map[string]*MetaValue
 }
@@ -114,7 +116,7 @@ func (this *MetaValues) Add(metaValue ...*MetaValue) {
 }
 
 func (this *MetaValues) CheckRequirement(context *core.Context, metaors ...Metaor) error {
-	if this.IsRequirementCheck() {
+	if !this.Patch && this.IsRequirementCheck() {
 		errors := core.Errors{}
 		for _, metaor := range metaors {
 			if !metaor.Proxier() {
@@ -144,6 +146,8 @@ type MetaValue struct {
 	Meta       Metaor
 	error      error
 	NoBlank    bool
+	// Null is true if value was explicitly cleared by a patch
+	Null bool
 }
 
 func (this *MetaValue) Path() string {
@@ -275,13 +279,21 @@ func decodeMetaValuesToField(res Resourcer, record interface{}, field reflect.Va
 			isPtr = true
 		}
 
-		for _, mv := range metaValue.MetaValues.Values {
+		diff := DiffOf(context)
+		for i, mv := range metaValue.MetaValues.Values {
 			var (
 				value                = reflect.New(fieldType)
 				associationProcessor = DecodeToResource(res, value.Interface(), mv, context)
 			)
 
-			if err = associationProcessor.Start(); err != nil {
+			if diff != nil {
+				leave := diff.enter(strconv.Itoa(i))
+				err = associationProcessor.Start()
+				leave()
+			} else {
+				err = associationProcessor.Start()
+			}
+			if err != nil {
 				return
 			}
 
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-aorm/aorm"
+)
+
+// OptimisticLock is the optimistic concurrency config of resource. The field is a version number (any int or
+// uint kind) or a timestamp (time.Time or *time.Time, like UpdatedAt).
+type OptimisticLock struct {
+	FieldName string
+	Field     *aorm.StructField
+	Timestamp bool
+}
+
+func (this *OptimisticLock) value(record interface{}) reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(this.Field.StructIndex)
+}
+
+// Current returns the current lock value of record
+func (this *OptimisticLock) Current(record interface{}) interface{} {
+	return this.value(record).Interface()
+}
+
+// Next sets the next lock value to record and returns a function to restore the previous value
+func (this *OptimisticLock) Next(record interface{}) (restore func()) {
+	var (
+		field = this.value(record)
+		old   = reflect.New(field.Type()).Elem()
+	)
+	old.Set(field)
+
+	if this.Timestamp {
+		now := time.Now()
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.ValueOf(&now))
+		} else {
+			field.Set(reflect.ValueOf(now))
+		}
+	} else {
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(field.Int() + 1)
+		default:
+			field.SetUint(field.Uint() + 1)
+		}
+	}
+	return func() {
+		field.Set(old)
+	}
+}
+
+// Where returns db with lock condition of value
+func (this *OptimisticLock) Where(res Resourcer, db *aorm.DB, value interface{}) *aorm.DB {
+	scope := db.NewScope(res.GetValue())
+	return db.Where(fmt.Sprintf("%v.%v = ?", scope.FromName(), scope.Quote(this.Field.DBName)), value)
+}
+
+// SetOptimisticLock enables optimistic concurrency on updates using the version or timestamp field
+func (res *Resource) SetOptimisticLock(fieldName string) error {
+	field, ok := res.ModelStruct.FieldsByName[fieldName]
+	if !ok {
+		return fmt.Errorf("%v is not a valid field for resource %v", fieldName, res.Name)
+	}
+	lock := &OptimisticLock{FieldName: fieldName, Field: field}
+	typ := field.Struct.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Struct.Type.Kind() == reflect.Ptr {
+			return fmt.Errorf("version field %v of resource %v can't be a pointer", fieldName, res.Name)
+		}
+	default:
+		if typ != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("%v of resource %v is not a version or timestamp field", fieldName, res.Name)
+		}
+		lock.Timestamp = true
+	}
+	res.OptimisticLock = lock
+	return nil
+}
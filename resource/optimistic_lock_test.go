@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-aorm/aorm"
+)
+
+type lockItem struct {
+	ID      int64 `aorm:"primary_key"`
+	Name    string
+	Version int
+}
+
+func lockResource(t *testing.T) (*Resource, *CRUD) {
+	db := testDB(t, &lockItem{})
+	res := New(&lockItem{}, "", "", nil)
+	if err := res.SetOptimisticLock("Version"); err != nil {
+		t.Fatal(err)
+	}
+	return res, res.Crud(testContext(db))
+}
+
+func TestSetOptimisticLock(t *testing.T) {
+	type item struct {
+		ID        int64 `aorm:"primary_key"`
+		Version   uint
+		VersionP  *int
+		Name      string
+		UpdatedAt *time.Time
+	}
+	cases := []struct {
+		field     string
+		err       bool
+		timestamp bool
+	}{
+		{"Version", false, false},
+		{"UpdatedAt", false, true},
+		{"VersionP", true, false},
+		{"Name", true, false},
+		{"Missing", true, false},
+	}
+	for _, c := range cases {
+		res := New(&item{}, "", "", nil)
+		err := res.SetOptimisticLock(c.field)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected error", c.field)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.field, err)
+		} else if res.OptimisticLock.Timestamp != c.timestamp {
+			t.Errorf("%s: expected timestamp %v", c.field, c.timestamp)
+		}
+	}
+}
+
+func TestOptimisticLock(t *testing.T) {
+	res, crud := lockResource(t)
+
+	record := &lockItem{Name: "a"}
+	if err := crud.CallCreate(record); err != nil {
+		t.Fatal(err)
+	}
+
+	var first, second lockItem
+	for _, r := range []*lockItem{&first, &second} {
+		if err := crud.FindOne(r, res.GetKey(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first.Name = "first"
+	if err := crud.CallUpdate(&first); err != nil {
+		t.Fatalf("first update: unexpected error: %v", err)
+	}
+	if first.Version != 1 {
+		t.Errorf("first update: expected version 1, but got %d", first.Version)
+	}
+
+	second.Name = "second"
+	err := crud.CallUpdate(&second)
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("stale update: expected conflict error, but got %v", err)
+	}
+	if current, _ := conflict.Current.(*lockItem); current == nil || current.Name != "first" || current.Version != 1 {
+		t.Errorf("stale update: expected current record of first update, but got %+v", conflict.Current)
+	}
+	if second.Version != 0 {
+		t.Errorf("stale update: expected version restored to 0, but got %d", second.Version)
+	}
+
+	if err = crud.DB().Delete(&first).Error; err != nil {
+		t.Fatal(err)
+	}
+	first.Name = "deleted"
+	if err = crud.CallUpdate(&first); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("deleted update: expected not found error, but got %v", err)
+	}
+}
+
+func TestOptimisticLockNoChanges(t *testing.T) {
+	res, crud := lockResource(t)
+
+	record := &lockItem{Name: "a"}
+	if err := crud.CallCreate(record); err != nil {
+		t.Fatal(err)
+	}
+	var stale lockItem
+	if err := crud.FindOne(&stale, res.GetKey(record)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := crud.CallUpdate(record, Snapshot(record)); err != nil {
+		t.Fatalf("current: unexpected error: %v", err)
+	}
+
+	record.Name = "b"
+	if err := crud.CallUpdate(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := crud.CallUpdate(&stale, Snapshot(&stale)); !IsConflictError(err) {
+		t.Errorf("stale: expected conflict error, but got %v", err)
+	}
+}
@@ -29,6 +29,8 @@ const (
 	ProcSkipLoad
 	ProcSkipChildLoad
 	ProcMerge
+	// ProcDryRun loads, sets and validates without writing and computes the diff (see DecodeDryRun)
+	ProcDryRun
 )
 
 func (b ProcessorFlag) Set(flag ProcessorFlag) ProcessorFlag    { return b | flag }
@@ -167,6 +169,8 @@ func (this *Processor) Initialize() (err error) {
 						} else if this.checkSkipLeft(err) {
 							err = nil
 						}
+					} else {
+						this.snapshot()
 					}
 				}
 			}
@@ -182,10 +186,15 @@ func (this *Processor) Initialize() (err error) {
 				} else if this.checkSkipLeft(err) {
 					err = nil
 				}
+			} else {
+				this.snapshot()
 			}
 		}
 
 		if this.deleted {
+			if diff := this.Diff(); diff != nil {
+				diff.add(&DiffEntry{Op: DiffDelete, Old: key})
+			}
 			this.Flag |= ProcSkipLeft
 			if this.parent != nil {
 				SliceMetaAppendDeleted(reflect.ValueOf(this.parent.Result), this.MetaValue.Parent.Name, key)
@@ -196,6 +205,11 @@ func (this *Processor) Initialize() (err error) {
 	return
 }
 
+// snapshot stores a copy of the loaded record, used by CRUD to detect the changed fields on update
+func (this *Processor) snapshot() {
+	this.Context.RecordSnapshots().Set(this.Result, Snapshot(this.Result))
+}
+
 func (this *Processor) Validate() error {
 	if this.checkSkipLeft() || this.Flag.Has(ProcSkipValidations) {
 		return nil
@@ -258,6 +272,22 @@ func (this *Processor) decode() (errors []error) {
 		set = func(metaValue *MetaValue) {
 			meta := metaValue.Meta
 
+			if metaValue.Null {
+				// the setter clears the value, so custom setters can clear related fields too
+				if setter := meta.GetSetter(); setter != nil {
+					if err := setter(this.Result, metaValue, this.Context); err != nil {
+						errors = append(errors, err)
+						return
+					}
+				} else if field := reflect.Indirect(reflect.ValueOf(this.Result)).FieldByName(meta.GetFieldName()); field.CanSet() {
+					field.Set(reflect.Zero(field.Type()))
+				}
+				if reqCheck && meta.IsRequired() {
+					errors = append(errors, ErrCantBeBlank(this.Context, this.Result, meta.GetName(), meta.GetRecordLabelC(this.Context, this.Result)))
+				}
+				return
+			}
+
 			if setter := meta.GetSetter(); setter != nil {
 				err := setter(this.Result, metaValue, this.Context)
 				if err != nil {
@@ -303,6 +333,8 @@ func (this *Processor) decode() (errors []error) {
 							this.checkSkipLeft(err)
 							errors = append(errors, err)
 						}
+					} else {
+						this.snapshot()
 					}
 				}
 			}()
@@ -350,6 +382,10 @@ func (this *Processor) decode() (errors []error) {
 				return meta.GetRecordLabelC(ctx, this.Result)
 			}, meta.GetName())()
 
+			if diff := DiffOf(ctx); diff != nil {
+				defer diff.enter(meta.GetName())()
+			}
+
 			if metaValue.MetaValues.Disabled {
 				field := reflect.Indirect(reflect.ValueOf(this.Result)).FieldByName(meta.GetFieldName())
 				field.Set(reflect.Zero(field.Type()))
@@ -391,7 +427,8 @@ func (this *Processor) Commit() error {
 		return errors
 	}
 
-	if this.Flag.Has(ProcSkipProcessors) {
+	if this.Flag.Has(ProcSkipProcessors) || IsDryRun(this.Context) {
+		// processors may have side effects, so they do not run on dry run
 		return nil
 	}
 
@@ -410,7 +447,17 @@ func (this *Processor) Commit() error {
 	return nil
 }
 
+// Diff returns the dry run diff of context (see DecodeDryRun)
+func (this *Processor) Diff() *Diff {
+	return DiffOf(this.Context)
+}
+
 func (this *Processor) Start() (err error) {
+	if this.Flag.Has(ProcDryRun) && this.Diff() == nil {
+		// started without DecodeDryRun: the diff lives while the processor runs
+		this.Context.SetValue(diffKey{}, &Diff{})
+		defer this.Context.DelValue(diffKey{})
+	}
 	if err = this.Initialize(); err != nil {
 		return
 	}
@@ -418,6 +465,10 @@ func (this *Processor) Start() (err error) {
 		return nil
 	}
 
+	if diff := this.Diff(); diff != nil {
+		return this.dryRun(diff)
+	}
+
 	if err = this.Validate(); err != nil {
 		return
 	}
@@ -428,6 +479,24 @@ func (this *Processor) Start() (err error) {
 	return
 }
 
+// dryRun validates and commits the meta values, not stopping on validation errors, and adds the changes to diff.
+// The old record is a deep snapshot, so setters changing values through pointers do not change it.
+func (this *Processor) dryRun(diff *Diff) error {
+	var (
+		errors core.Errors
+		old    = Snapshot(this.Result)
+	)
+	errors.AddError(this.Validate())
+	errors.AddError(this.Commit())
+	if this.MetaValues != nil {
+		diffMetaValues(this.Context, diff, this.MetaValues, old, this.Result)
+	}
+	if errors.HasError() {
+		return errors
+	}
+	return nil
+}
+
 type ResourcerMetaValuesBeforeCommiter interface {
 	BeforeCommitMetaValues(ctx *core.Context, record interface{}, metaValues *MetaValues)
 }
@@ -0,0 +1,321 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/roles"
+	"github.com/go-aorm/aorm"
+)
+
+// FilterOp is a query filter operator
+type FilterOp string
+
+const (
+	FilterEq         FilterOp = "eq"
+	FilterNe         FilterOp = "ne"
+	FilterLt         FilterOp = "lt"
+	FilterLte        FilterOp = "lte"
+	FilterGt         FilterOp = "gt"
+	FilterGte        FilterOp = "gte"
+	FilterContains   FilterOp = "contains"
+	FilterStartsWith FilterOp = "starts_with"
+	FilterEndsWith   FilterOp = "ends_with"
+	FilterIn         FilterOp = "in"
+	FilterNotIn      FilterOp = "nin"
+	FilterNull       FilterOp = "null"
+)
+
+var (
+	filterOpSQL = map[FilterOp]string{
+		FilterEq:         "=",
+		FilterNe:         "<>",
+		FilterLt:         "<",
+		FilterLte:        "<=",
+		FilterGt:         ">",
+		FilterGte:        ">=",
+		FilterContains:   "LIKE",
+		FilterStartsWith: "LIKE",
+		FilterEndsWith:   "LIKE",
+		FilterIn:         "IN",
+		FilterNotIn:      "NOT IN",
+	}
+
+	filterParamRe = regexp.MustCompile(`^filter\[([^\]]+)\](?:\[([^\]]+)\])?$`)
+	likeEscaper   = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+)
+
+// QueryFilter is a filter of query. Path is the dot separated meta names path.
+type QueryFilter struct {
+	Path   string
+	Op     FilterOp
+	Values []string
+}
+
+// QuerySort is a sort of query
+type QuerySort struct {
+	Path string
+	Desc bool
+}
+
+// Query is the parsed resource filter and sort query
+type Query struct {
+	Filters []QueryFilter
+	Sorts   []QuerySort
+}
+
+// QueryError is the error of invalid query parameter
+type QueryError struct {
+	Param string
+	Err   error
+}
+
+func (this *QueryError) Error() string {
+	return fmt.Sprintf("resource: invalid query parameter %q: %v", this.Param, this.Err)
+}
+
+func (this *QueryError) Cause() error {
+	return this.Err
+}
+
+// ParseQuery parses query values such as `filter[name][contains]=x&filter[created_at][gte]=2020-01-01&sort=-created_at`.
+// The default filter operator is `eq`. The `in` and `nin` operators accepts comma separated values.
+func ParseQuery(values url.Values) (query *Query, err error) {
+	query = &Query{}
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "sort" {
+			for _, value := range values[key] {
+				for _, path := range strings.Split(value, ",") {
+					if path = strings.TrimSpace(path); path == "" {
+						continue
+					}
+					s := QuerySort{Path: path}
+					if strings.HasPrefix(path, "-") {
+						s.Desc, s.Path = true, path[1:]
+					}
+					query.Sorts = append(query.Sorts, s)
+				}
+			}
+			continue
+		}
+
+		match := filterParamRe.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		op := FilterOp(match[2])
+		if op == "" {
+			op = FilterEq
+		} else if _, ok := filterOpSQL[op]; !ok && op != FilterNull {
+			return nil, &QueryError{key, fmt.Errorf("unknown operator %q", op)}
+		}
+
+		for _, value := range values[key] {
+			f := QueryFilter{Path: match[1], Op: op, Values: []string{value}}
+			if op == FilterIn || op == FilterNotIn {
+				f.Values = strings.Split(value, ",")
+			}
+			query.Filters = append(query.Filters, f)
+		}
+	}
+	return
+}
+
+// Apply applies the query conditions and orders into DB. Only metas returned by `GetMetas` and readable by context
+// are allowed. Paths are matched by meta name or field database name; relations are filtered by sub queries.
+func (this *Query) Apply(res Resourcer, ctx *core.Context, DB *aorm.DB) (_ *aorm.DB, err error) {
+	for _, f := range this.Filters {
+		var (
+			cond string
+			args []interface{}
+		)
+		if cond, args, err = queryFilterCondition(res, ctx, DB, strings.Split(f.Path, "."), f); err != nil {
+			return nil, &QueryError{"filter[" + f.Path + "]", err}
+		}
+		DB = DB.Where(cond, args...)
+	}
+
+	var orders []PageOrder
+	if orders, err = this.pageOrder(res, ctx); err != nil {
+		return
+	}
+	scope := DB.NewScope(res.GetValue())
+	for _, order := range orders {
+		dir := "ASC"
+		if order.Desc {
+			dir = "DESC"
+		}
+		DB = DB.Order(fmt.Sprintf("%v.%v %v", scope.QuotedTableName(), scope.Quote(order.Field.DBName), dir))
+	}
+	return DB, nil
+}
+
+// pageOrder returns the sorts as order fields
+func (this *Query) pageOrder(res Resourcer, ctx *core.Context) (orders []PageOrder, err error) {
+	for _, s := range this.Sorts {
+		var meta Metaor
+		if meta, err = queryMeta(res, ctx, s.Path); err == nil && meta.GetFieldStruct().Relationship != nil {
+			err = fmt.Errorf("sort by relation is not supported")
+		}
+		if err != nil {
+			return nil, &QueryError{"sort", err}
+		}
+		orders = append(orders, PageOrder{Field: meta.GetFieldStruct(), Desc: s.Desc})
+	}
+	return
+}
+
+// Query parses query values and returns a sub CRUD with the query applied to DB. The sort is also used as the Page
+// order.
+func (this *CRUD) Query(values url.Values) (_ *CRUD, err error) {
+	var (
+		query  *Query
+		DB     *aorm.DB
+		orders []PageOrder
+	)
+	if query, err = ParseQuery(values); err != nil {
+		return
+	}
+	if DB, err = query.Apply(this.res, this.context, this.DB()); err != nil {
+		return
+	}
+	orders, _ = query.pageOrder(this.res, this.context)
+	crud := this.SetContext(this.context.Clone().SetDB(DB))
+	crud.sorts = orders
+	return crud, nil
+}
+
+func queryMeta(res Resourcer, ctx *core.Context, name string) (meta Metaor, err error) {
+	if _, ok := res.(*Resource); !ok {
+		for _, m := range res.GetMetas([]string{}) {
+			if m.GetName() == name || (m.GetFieldStruct() != nil && m.GetFieldStruct().DBName == name) {
+				meta = m
+				break
+			}
+		}
+	}
+	if meta == nil || meta.GetFieldStruct() == nil || meta.GetFieldStruct().IsIgnored {
+		return nil, fmt.Errorf("unknown field %q", name)
+	}
+	if meta.HasPermission(roles.Read, ctx) == roles.DENY {
+		return nil, fmt.Errorf("field %q is not readable", name)
+	}
+	return
+}
+
+func queryFilterCondition(res Resourcer, ctx *core.Context, DB *aorm.DB, path []string, f QueryFilter) (cond string, args []interface{}, err error) {
+	var (
+		meta  Metaor
+		scope = DB.NewScope(res.GetValue())
+		table = scope.QuotedTableName()
+	)
+	if meta, err = queryMeta(res, ctx, path[0]); err != nil {
+		return
+	}
+	field := meta.GetFieldStruct()
+
+	if rel := field.Relationship; rel != nil {
+		if len(path) == 1 {
+			return "", nil, fmt.Errorf("%q is a relation", path[0])
+		}
+		relRes := meta.GetResource()
+		if relRes == nil {
+			return "", nil, fmt.Errorf("%q does not have resource", path[0])
+		}
+		if len(rel.ForeignDBNames) != 1 || len(rel.AssociationForeignDBNames) != 1 {
+			return "", nil, fmt.Errorf("relation %q with composite keys is not supported", path[0])
+		}
+		if cond, args, err = queryFilterCondition(relRes, ctx, DB, path[1:], f); err != nil {
+			return
+		}
+		relTable := DB.NewScope(relRes.GetValue()).QuotedTableName()
+		switch rel.Kind {
+		case aorm.BELONGS_TO:
+			cond = fmt.Sprintf("%v.%v IN (SELECT %v.%v FROM %v WHERE %v)", table, scope.Quote(rel.ForeignDBNames[0]),
+				relTable, scope.Quote(rel.AssociationForeignDBNames[0]), relTable, cond)
+		case aorm.HAS_ONE, aorm.HAS_MANY:
+			cond = fmt.Sprintf("%v.%v IN (SELECT %v.%v FROM %v WHERE %v)", table, scope.Quote(rel.AssociationForeignDBNames[0]),
+				relTable, scope.Quote(rel.ForeignDBNames[0]), relTable, cond)
+		default:
+			return "", nil, fmt.Errorf("relation %q of kind %q is not supported", path[0], rel.Kind)
+		}
+		return
+	}
+
+	if len(path) > 1 {
+		return "", nil, fmt.Errorf("%q is not a relation", path[0])
+	}
+
+	column := table + "." + scope.Quote(field.DBName)
+
+	switch f.Op {
+	case FilterNull:
+		var isNull bool
+		if isNull, err = strconv.ParseBool(f.Values[0]); err != nil {
+			return
+		}
+		if isNull {
+			return column + " IS NULL", nil, nil
+		}
+		return column + " IS NOT NULL", nil, nil
+	case FilterContains:
+		return column + ` LIKE ? ESCAPE '!'`, []interface{}{"%" + likeEscaper.Replace(f.Values[0]) + "%"}, nil
+	case FilterStartsWith:
+		return column + ` LIKE ? ESCAPE '!'`, []interface{}{likeEscaper.Replace(f.Values[0]) + "%"}, nil
+	case FilterEndsWith:
+		return column + ` LIKE ? ESCAPE '!'`, []interface{}{"%" + likeEscaper.Replace(f.Values[0])}, nil
+	}
+
+	values := make([]interface{}, len(f.Values))
+	for i, s := range f.Values {
+		if values[i], err = queryValue(field.Struct.Type, s); err != nil {
+			return
+		}
+	}
+
+	if f.Op == FilterIn || f.Op == FilterNotIn {
+		return column + " " + filterOpSQL[f.Op] + " (?)", []interface{}{values}, nil
+	}
+	return column + " " + filterOpSQL[f.Op] + " ?", values, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// queryValue parses the string value as value of typ
+func queryValue(typ reflect.Type, s string) (interface{}, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %q", s)
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	}
+	return s, nil
+}
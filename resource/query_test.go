@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/roles"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   *Query
+		err    bool
+	}{
+		{"empty", url.Values{"page[size]": {"10"}}, &Query{}, false},
+		{"default op", url.Values{"filter[name]": {"x"}}, &Query{Filters: []QueryFilter{{"name", FilterEq, []string{"x"}}}}, false},
+		{"op", url.Values{"filter[name][contains]": {"x"}}, &Query{Filters: []QueryFilter{{"name", FilterContains, []string{"x"}}}}, false},
+		{"in", url.Values{"filter[id][in]": {"1,2"}}, &Query{Filters: []QueryFilter{{"id", FilterIn, []string{"1", "2"}}}}, false},
+		{"null", url.Values{"filter[name][null]": {"true"}}, &Query{Filters: []QueryFilter{{"name", FilterNull, []string{"true"}}}}, false},
+		{"path", url.Values{"filter[category.name]": {"x"}}, &Query{Filters: []QueryFilter{{"category.name", FilterEq, []string{"x"}}}}, false},
+		{"unknown op", url.Values{"filter[name][like]": {"x"}}, nil, true},
+		{"sort", url.Values{"sort": {"-name, id,"}}, &Query{Sorts: []QuerySort{{"name", true}, {"id", false}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.values)
+			if tt.err {
+				if _, ok := err.(*QueryError); !ok {
+					t.Errorf("expected query error, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, but got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+type queryItem struct {
+	ID     int64 `aorm:"primary_key"`
+	Name   string
+	Secret string
+}
+
+// deniedMeta is a meta without read permission
+type deniedMeta struct {
+	*Meta
+}
+
+func (this *deniedMeta) HasPermission(roles.PermissionMode, *core.Context) roles.Perm {
+	return roles.DENY
+}
+
+func TestQueryApply(t *testing.T) {
+	db := testDB(t, &queryItem{})
+	res := newMetasResource(&queryItem{}, "ID", "Name")
+	res.metas = append(res.metas, &deniedMeta{&Meta{MetaName: &MetaName{Name: "Secret"}, FieldName: "Secret",
+		FieldStruct: res.ModelStruct.FieldsByName["Secret"]}})
+	for _, name := range []string{"a_b", "a%c", "abc", "x!y"} {
+		if err := NewCrud(res, testContext(db)).Create(&queryItem{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		want   string
+		err    string
+	}{
+		{"all", url.Values{}, "a_b,a%c,abc,x!y", ""},
+		{"eq", url.Values{"filter[name]": {"abc"}}, "abc", ""},
+		{"ne", url.Values{"filter[name][ne]": {"abc"}}, "a_b,a%c,x!y", ""},
+		{"contains escaped underscore", url.Values{"filter[name][contains]": {"_"}}, "a_b", ""},
+		{"contains escaped percent", url.Values{"filter[name][contains]": {"%"}}, "a%c", ""},
+		{"contains escape char", url.Values{"filter[name][contains]": {"!"}}, "x!y", ""},
+		{"starts with", url.Values{"filter[name][starts_with]": {"a"}}, "a_b,a%c,abc", ""},
+		{"ends with", url.Values{"filter[name][ends_with]": {"c"}}, "a%c,abc", ""},
+		{"gt", url.Values{"filter[id][gt]": {"2"}}, "abc,x!y", ""},
+		{"lte", url.Values{"filter[ID][lte]": {"2"}}, "a_b,a%c", ""},
+		{"in", url.Values{"filter[id][in]": {"1,3"}}, "a_b,abc", ""},
+		{"not in", url.Values{"filter[id][nin]": {"1,3"}}, "a%c,x!y", ""},
+		{"null", url.Values{"filter[name][null]": {"true"}}, "", ""},
+		{"not null", url.Values{"filter[name][null]": {"false"}}, "a_b,a%c,abc,x!y", ""},
+		{"sort", url.Values{"sort": {"-id"}}, "x!y,abc,a%c,a_b", ""},
+		{"bad value", url.Values{"filter[id]": {"x"}}, "", "filter[id]"},
+		{"unknown field", url.Values{"filter[unknown]": {"x"}}, "", "unknown field"},
+		{"not a relation", url.Values{"filter[name.id]": {"x"}}, "", "is not a relation"},
+		{"denied field", url.Values{"filter[secret]": {"x"}}, "", "is not readable"},
+		{"denied sort", url.Values{"sort": {"secret"}}, "", "is not readable"},
+		{"unknown sort", url.Values{"sort": {"unknown"}}, "", "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.values["sort"]; !ok {
+				tt.values.Set("sort", "id")
+			}
+			crud := NewCrud(res, testContext(db))
+			query, err := crud.Query(tt.values)
+			if tt.err != "" {
+				if _, ok := err.(*QueryError); !ok || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected query error %q, but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.DB() == crud.DB() {
+				t.Errorf("query changes the receiver DB")
+			}
+			var (
+				items []queryItem
+				names []string
+			)
+			if err = query.FindMany(&items); err != nil {
+				t.Fatal(err)
+			}
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("expected %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
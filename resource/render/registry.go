@@ -0,0 +1,128 @@
+package render
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ecletus/core/resource"
+)
+
+type entry struct {
+	mediaType string
+	renderer  Renderer
+}
+
+// Registry is the registry of renderers by media type. The first registered renderer is used for `*/*`.
+type Registry struct {
+	mu      sync.RWMutex
+	entries []*entry
+}
+
+// Register registers renderer for media types, replacing the previous renderers of them
+func (this *Registry) Register(renderer Renderer, mediaType ...string) *Registry {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+main:
+	for _, mediaType := range mediaType {
+		mediaType = strings.ToLower(mediaType)
+		for _, e := range this.entries {
+			if e.mediaType == mediaType {
+				e.renderer = renderer
+				continue main
+			}
+		}
+		this.entries = append(this.entries, &entry{mediaType, renderer})
+	}
+	return this
+}
+
+// Get returns the renderer of media type
+func (this *Registry) Get(mediaType string) Renderer {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	mediaType = strings.ToLower(mediaType)
+	for _, e := range this.entries {
+		if e.mediaType == mediaType {
+			return e.renderer
+		}
+	}
+	return nil
+}
+
+// Negotiate returns the renderer of the most preferred media type of Accept header. Blank accept is `*/*`.
+func (this *Registry) Negotiate(accept string) (mediaType string, renderer Renderer) {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	for _, accepted := range ParseAccept(accept) {
+		for _, e := range this.entries {
+			if accepted == "*/*" ||
+				accepted == e.mediaType ||
+				(strings.HasSuffix(accepted, "/*") && strings.HasPrefix(e.mediaType, accepted[:len(accepted)-1])) {
+				return e.mediaType, e.renderer
+			}
+		}
+	}
+	return
+}
+
+// ParseAccept returns the media types of Accept header ordered by quality
+func ParseAccept(accept string) (mediaTypes []string) {
+	if strings.TrimSpace(accept) == "" {
+		return []string{"*/*"}
+	}
+	type item struct {
+		mediaType string
+		q         float64
+	}
+	var items []item
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			items = append(items, item{mediaType, q})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+	for _, item := range items {
+		mediaTypes = append(mediaTypes, item.mediaType)
+	}
+	return
+}
+
+type registryKey struct{}
+
+var ofMu sync.Mutex
+
+// Of returns the custom renderers registry of res. If create, creates it if not exists.
+func Of(res resource.Resourcer, create bool) *Registry {
+	r := res.GetResource()
+	ofMu.Lock()
+	defer ofMu.Unlock()
+	if v, ok := r.ConfigGet(registryKey{}); ok {
+		return v.(*Registry)
+	}
+	if !create {
+		return nil
+	}
+	reg := &Registry{}
+	r.ConfigSet(registryKey{}, reg)
+	return reg
+}
+
+// Register registers a custom renderer of res
+func Register(res resource.Resourcer, renderer Renderer, mediaType ...string) {
+	Of(res, true).Register(renderer, mediaType...)
+}
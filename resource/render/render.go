@@ -0,0 +1,222 @@
+// Package render serializes layout results by the content negotiated from the `Accept` header.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+	"github.com/ecletus/core/resource/exchange"
+	"github.com/ecletus/core/utils"
+)
+
+// ErrNotAcceptable is returned when none of the accepted media types has a renderer
+var ErrNotAcceptable = errors.New("render: not acceptable")
+
+// Renderer serializes the rendering into w
+type Renderer interface {
+	Render(w io.Writer, r *Rendering) error
+}
+
+// RendererFunc is a func Renderer
+type RendererFunc func(w io.Writer, r *Rendering) error
+
+func (f RendererFunc) Render(w io.Writer, r *Rendering) error {
+	return f(w, r)
+}
+
+// Field is a formatted meta value of record
+type Field struct {
+	Name     string
+	Label    string
+	Value    interface{}
+	Text     string
+	Severity core.Severity
+}
+
+// Rendering is the layout result to be rendered
+type Rendering struct {
+	Context  *core.Context
+	Resource resource.Resourcer
+	// Result is the layout result: a record or a slice of records
+	Result interface{}
+	// Metas are the metas of fields. If blank, uses the readable context metas of Resource. If set, structured
+	// renderers (JSON, MessagePack) serializes the fields instead of Result.
+	Metas []resource.Metaor
+
+	rows [][]*Field
+}
+
+// IsMany returns if Result is a slice of records
+func (this *Rendering) IsMany() bool {
+	if this.Result == nil {
+		return false
+	}
+	kind := reflect.Indirect(reflect.ValueOf(this.Result)).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// Records returns the records of Result
+func (this *Rendering) Records() (records []interface{}) {
+	if this.Result == nil {
+		return
+	}
+	if !this.IsMany() {
+		return []interface{}{this.Result}
+	}
+	value := reflect.Indirect(reflect.ValueOf(this.Result))
+	records = make([]interface{}, value.Len())
+	for i := range records {
+		r := value.Index(i)
+		if r.Kind() == reflect.Interface {
+			// such as []BasicValuer of basic layouts
+			r = r.Elem()
+		} else if r.Kind() != reflect.Ptr && r.CanAddr() {
+			r = r.Addr()
+		}
+		records[i] = r.Interface()
+	}
+	return
+}
+
+// isModel returns if record is an instance of Resource model. Layouts, such as the basic layout, may format the
+// records to other types, which the meta valuers do not accept.
+func (this *Rendering) isModel(record interface{}) bool {
+	if this.Resource == nil || record == nil {
+		return false
+	}
+	return utils.ModelType(record) == utils.ModelType(this.Resource.GetValue())
+}
+
+// GetMetas returns the metas of fields
+func (this *Rendering) GetMetas() []resource.Metaor {
+	if this.Metas == nil && this.Resource != nil {
+		if _, ok := this.Resource.(*resource.Resource); !ok {
+			this.Metas = exchange.ReadableMetas(this.Context, this.Resource)
+		}
+	}
+	return this.Metas
+}
+
+// Labels returns the labels of fields
+func (this *Rendering) Labels() (labels []string) {
+	if rows := this.Rows(); len(rows) > 0 {
+		for _, field := range rows[0] {
+			labels = append(labels, field.Label)
+		}
+		return
+	}
+	for _, meta := range this.GetMetas() {
+		labels = append(labels, meta.GetLabelC(this.Context))
+	}
+	return
+}
+
+// Rows returns the formatted fields of records. Records of model are formatted by metas, basic values (see
+// resource.BasicValuer) by their ID, label and description, and other values as a single `value` field.
+func (this *Rendering) Rows() [][]*Field {
+	if this.rows != nil {
+		return this.rows
+	}
+	metas := this.GetMetas()
+	this.rows = [][]*Field{}
+	for _, record := range this.Records() {
+		if !this.isModel(record) {
+			this.rows = append(this.rows, valueFields(record))
+			continue
+		}
+		row := make([]*Field, len(metas))
+		for i, meta := range metas {
+			field := &Field{Name: meta.GetName(), Label: meta.GetLabelC(this.Context)}
+			if fv := meta.GetFormattedValuer()(record, this.Context); fv != nil {
+				meta.Severitify(fv)
+				field.Value, field.Text, field.Severity = exchange.Value(fv), exchange.Text(fv), fv.Severity
+			}
+			row[i] = field
+		}
+		this.rows = append(this.rows, row)
+	}
+	return this.rows
+}
+
+func valueFields(record interface{}) []*Field {
+	basic, ok := record.(resource.BasicValuer)
+	if !ok {
+		return []*Field{{Name: "value", Label: "Value", Value: record, Text: fmt.Sprint(record)}}
+	}
+	var id string
+	if key := basic.GetID(); key != nil {
+		id = key.String()
+	}
+	fields := []*Field{
+		{Name: "id", Label: "ID", Value: id, Text: id},
+		{Name: "label", Label: "Label", Value: basic.BasicLabel(), Text: basic.BasicLabel()},
+	}
+	if d, ok := record.(resource.BasicDescriptableValuer); ok {
+		fields = append(fields, &Field{Name: "description", Label: "Description", Value: d.BasicDescription(), Text: d.BasicDescription()})
+	}
+	return fields
+}
+
+// Data returns the structured data: Result if Metas is blank, otherwise the fields of records by meta name. Fields
+// with severity are objects with `value`, `text` and `severity` keys.
+func (this *Rendering) Data() interface{} {
+	if len(this.Metas) == 0 {
+		return this.Result
+	}
+	var records []map[string]interface{}
+	for _, row := range this.Rows() {
+		record := make(map[string]interface{}, len(row))
+		for _, field := range row {
+			if field.Severity == core.Default {
+				record[field.Name] = field.Value
+			} else {
+				record[field.Name] = map[string]interface{}{
+					"value":    field.Value,
+					"text":     field.Text,
+					"severity": field.Severity.String(),
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	if !this.IsMany() {
+		if len(records) == 0 {
+			return nil
+		}
+		return records[0]
+	}
+	return records
+}
+
+// Negotiate returns the renderer of Accept header, from registry of res and then from DefaultRegistry
+func Negotiate(res resource.Resourcer, accept string) (mediaType string, renderer Renderer) {
+	if res != nil {
+		if reg := Of(res, false); reg != nil {
+			if mediaType, renderer = reg.Negotiate(accept); renderer != nil {
+				return
+			}
+		}
+	}
+	return DefaultRegistry.Negotiate(accept)
+}
+
+// Write negotiates the renderer by request `Accept` header and writes the rendering with status
+func Write(w http.ResponseWriter, status int, r *Rendering) error {
+	mediaType, renderer := Negotiate(r.Resource, r.Context.Request.Header.Get("Accept"))
+	if renderer == nil {
+		return ErrNotAcceptable
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") {
+		mediaType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	return renderer.Render(w, r)
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// JSONRenderer renders Rendering.Data as JSON
+var JSONRenderer = RendererFunc(func(w io.Writer, r *Rendering) error {
+	return json.NewEncoder(w).Encode(r.Data())
+})
+
+// MarshalRenderer returns a renderer of Rendering.Data by marshal func. It adapts the encoders of third party
+// formats, such as the default MessagePack renderer:
+//
+//	render.DefaultRegistry.Register(render.MarshalRenderer(msgpack.Marshal), "application/msgpack")
+func MarshalRenderer(marshal func(v interface{}) ([]byte, error)) Renderer {
+	return RendererFunc(func(w io.Writer, r *Rendering) error {
+		data, err := marshal(r.Data())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
+
+type xmlField struct {
+	Name     string `xml:"name,attr"`
+	Severity string `xml:"severity,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type xmlRecord struct {
+	Fields []xmlField `xml:"field"`
+}
+
+type xmlRecords struct {
+	XMLName xml.Name    `xml:"records"`
+	Records []xmlRecord `xml:"record"`
+}
+
+// XMLRenderer renders the rows as `<records><record><field name="..." severity="...">text</field></record></records>`
+var XMLRenderer = RendererFunc(func(w io.Writer, r *Rendering) error {
+	var doc xmlRecords
+	for _, row := range r.Rows() {
+		var record xmlRecord
+		for _, field := range row {
+			f := xmlField{Name: field.Name, Text: field.Text}
+			if field.Severity != 0 {
+				f.Severity = field.Severity.String()
+			}
+			record.Fields = append(record.Fields, f)
+		}
+		doc.Records = append(doc.Records, record)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(doc)
+})
+
+// CSVRenderer renders the rows as CSV with the header of labels
+var CSVRenderer = RendererFunc(func(w io.Writer, r *Rendering) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Labels()); err != nil {
+		return err
+	}
+	for _, row := range r.Rows() {
+		record := make([]string, len(row))
+		for i, field := range row {
+			record[i] = field.Text
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+})
+
+// DefaultHTMLTemplate is the template of default HTML renderer. It executes with *Rendering.
+var DefaultHTMLTemplate = template.Must(template.New("render").Parse(`<table>
+<thead><tr>{{range .Labels}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>{{range .Rows}}
+<tr>{{range .}}<td data-name="{{.Name}}"{{if .Severity}} class="severity-{{.Severity}}"{{end}}>{{.Text}}</td>{{end}}</tr>{{end}}
+</tbody>
+</table>
+`))
+
+// HTMLRenderer renders Rendering by template
+type HTMLRenderer struct {
+	Template *template.Template
+}
+
+func (this *HTMLRenderer) Render(w io.Writer, r *Rendering) error {
+	t := this.Template
+	if t == nil {
+		t = DefaultHTMLTemplate
+	}
+	return t.Execute(w, r)
+}
+
+// DefaultRegistry is the registry of default renderers
+var DefaultRegistry = &Registry{}
+
+func init() {
+	DefaultRegistry.
+		Register(JSONRenderer, "application/json").
+		Register(XMLRenderer, "application/xml", "text/xml").
+		Register(CSVRenderer, "text/csv").
+		Register(MarshalRenderer(msgpack.Marshal), "application/msgpack", "application/x-msgpack").
+		Register(&HTMLRenderer{}, "text/html")
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ecletus/core/resource"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMarshalRenderer(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		data = map[string]interface{}{"a": 1}
+	)
+	if err := MarshalRenderer(json.Marshal).Render(&buf, &Rendering{Result: data}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `{"a":1}`; got != want {
+		t.Errorf("expected %q, but got %q", want, got)
+	}
+}
+
+func TestMsgpackRenderer(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		data = map[string]interface{}{"a": "x"}
+		got  map[string]interface{}
+	)
+	renderer := DefaultRegistry.Get("application/msgpack")
+	if renderer == nil {
+		t.Fatal("msgpack renderer not registered")
+	}
+	if err := renderer.Render(&buf, &Rendering{Result: data}); err != nil {
+		t.Fatal(err)
+	}
+	if err := msgpack.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("expected %v, but got %v", data, got)
+	}
+}
+
+func TestRowsOfBasicValues(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		r   = &Rendering{Result: []resource.BasicValuer{&resource.Basic{Label: "a"}, &resource.Basic{Label: "b"}}}
+	)
+	if err := CSVRenderer.Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "ID,Label\n,a\n,b\n"; got != want {
+		t.Errorf("expected %q, but got %q", want, got)
+	}
+}
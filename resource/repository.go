@@ -1,11 +1,55 @@
 package resource
 
 import (
+	"errors"
+
 	"github.com/ecletus/core"
 	"github.com/go-aorm/aorm"
 	"github.com/moisespsena-go/edis"
 )
 
+// ErrUnsupportedRepository is returned by CRUD operations that query aorm directly when the resource repository
+// isn't an AormRepository
+var ErrUnsupportedRepository = errors.New("resource: operation not supported by repository")
+
+// RecordRepository is the records storage of CRUD. DB events and permissions are handled by CRUD, so the
+// repository only reads and writes records. Not found records returns aorm.ErrRecordNotFound.
+type RecordRepository interface {
+	// FindOne loads the record by id into result. If id is nil, loads the singleton record.
+	FindOne(ctx *RepositoryContext, result interface{}, id aorm.ID) error
+	// FindMany loads all records into the slice pointer result
+	FindMany(ctx *RepositoryContext, result interface{}) error
+	// Count sets the count of records into the integer pointer result
+	Count(ctx *RepositoryContext, result interface{}) error
+	Create(ctx *RepositoryContext, record interface{}) error
+	Update(ctx *RepositoryContext, record interface{}) error
+	Delete(ctx *RepositoryContext, record interface{}) error
+}
+
+// RecordClient is the records client bound to context and layout, implemented by CRUD
+type RecordClient interface {
+	edis.EventDispatcherInterface
+	Resource() Resourcer
+	Context() *core.Context
+	Layout() LayoutInterface
+	FindOne(result interface{}, key ...aorm.ID) error
+	FindMany(result interface{}) error
+	FindOneLayout(key aorm.ID, layout ...interface{}) (result interface{}, err error)
+	FindManyLayout(layout ...interface{}) (result interface{}, err error)
+	FindOneBasic(key aorm.ID) (result BasicValuer, err error)
+	FindManyBasic() (result []BasicValuer, err error)
+	Count(result interface{}) error
+	Create(record interface{}) error
+	Update(record interface{}, old ...interface{}) error
+	SaveOrCreate(recorde interface{}) error
+	Delete(record interface{}) error
+}
+
+var _ RecordClient = (*CRUD)(nil)
+
+// Repository is the string keyed records client.
+//
+// Deprecated: kept for compatibility, use RecordClient (implemented by CRUD) or RecordRepository (the storage).
 type Repository interface {
 	FindOneLayout(key string, layout ...interface{}) (result interface{}, err error)
 	FindManyLayout(layout ...interface{}) (result interface{}, err error)
@@ -20,6 +64,9 @@ type Repository interface {
 	Delete(record interface{}) (err error)
 }
 
+// RepositoryClient is the string keyed records client with setters.
+//
+// Deprecated: kept for compatibility, use RecordClient.
 type RepositoryClient interface {
 	Repository
 	edis.EventDispatcherInterface
@@ -36,3 +83,19 @@ type RepositoryClient interface {
 	SetLayoutOrDefault(layout interface{}, defaul ...interface{}) RepositoryClient
 	SetContext(ctx *core.Context) RepositoryClient
 }
+
+// DefaultRepository is the repository of resources without repository
+var DefaultRepository RecordRepository = &AormRepository{}
+
+// SetRepository sets the records repository of resource
+func (res *Resource) SetRepository(repository RecordRepository) {
+	res.Repository = repository
+}
+
+// GetRepository returns the records repository of resource or DefaultRepository
+func (res *Resource) GetRepository() RecordRepository {
+	if res.Repository != nil {
+		return res.Repository
+	}
+	return DefaultRepository
+}
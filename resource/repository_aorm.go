@@ -0,0 +1,59 @@
+package resource
+
+import "github.com/go-aorm/aorm"
+
+// AormRepository is the aorm records repository
+type AormRepository struct{}
+
+func (AormRepository) FindOne(ctx *RepositoryContext, result interface{}, id aorm.ID) error {
+	DB := ctx.DB()
+	if id != nil {
+		DB = DB.Where(id)
+	}
+	return DB.First(result).Error
+}
+
+func (AormRepository) FindMany(ctx *RepositoryContext, result interface{}) (err error) {
+	DB := ctx.DB()
+	if !DB.HasOrder() {
+		if orders := ctx.CRUD.res.GetModelStruct().Orders; len(orders) > 0 {
+			DB = DB.Order(orders)
+		} else if order := ctx.CRUD.res.DefaultPrimaryKeyOrder(); order != 0 {
+			DB = DB.Set("gorm:order_by_primary_key", order.String())
+		}
+	}
+	DB = DB.Find(result)
+	ctx.Context.DB(DB)
+	return DB.Error
+}
+
+func (AormRepository) Count(ctx *RepositoryContext, result interface{}) error {
+	return ctx.DB().Count(result).Error
+}
+
+func (AormRepository) Create(ctx *RepositoryContext, record interface{}) error {
+	return ctx.DB().Create(record).Error
+}
+
+// Update updates only the changed columns if event has old record, and checks the optimistic lock. Records with
+// ID are updated with the event conditions, instead of `DB.Save` that creates the record when the conditions
+// don't match it: if no record matches, returns aorm.ErrRecordNotFound. Records without ID are created by
+// `DB.Save`.
+func (AormRepository) Update(ctx *RepositoryContext, record interface{}) error {
+	var (
+		DB   = ctx.DB()
+		lock = ctx.CRUD.res.GetResource().OptimisticLock
+	)
+	if ctx.Event.old != nil {
+		return ctx.CRUD.updateChanges(DB, record, ctx.Event.Changes(), lock)
+	} else if lock != nil {
+		return ctx.CRUD.updateLocked(DB, record, lock, nil)
+	} else if !aorm.ZeroIdOf(record) {
+		return ctx.CRUD.checkUpdated(DB, DB.Model(record).Opt(aorm.OptStoreBlankFields()).Update(record), record)
+	}
+	return DB.Save(record).Error
+}
+
+func (AormRepository) Delete(ctx *RepositoryContext, record interface{}) error {
+	return ctx.DB().Delete(record).Error
+}
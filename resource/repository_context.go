@@ -1,5 +1,19 @@
 package resource
 
+import "github.com/ecletus/core"
+
+// RepositoryContext is the context of repository operation. The Context DB contains the conditions applied by
+// the DB events.
 type RepositoryContext struct {
+	*core.Context
+	CRUD  *CRUD
+	Event *DBEvent
+}
+
+func (this *CRUD) repositoryContext(e *DBEvent) *RepositoryContext {
+	return &RepositoryContext{e.Context, this, e}
+}
 
+func (this *CRUD) repository() RecordRepository {
+	return this.res.GetResource().GetRepository()
 }
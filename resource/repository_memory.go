@@ -0,0 +1,258 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// MemoryRepository stores records in memory, ordered by key. If Path is not blank, the records are loaded from
+// and saved into the JSON file Path, as an array of records. DB conditions are ignored, so DB scoped features
+// such as tenancy, soft delete, search and keyset pagination requires the aorm repository. The operations of
+// resources with TenantScope or SoftDelete return ErrUnsupportedRepository.
+type MemoryRepository struct {
+	Path string
+
+	mu      sync.RWMutex
+	loaded  bool
+	records map[string]reflect.Value
+}
+
+// NewMemoryRepository creates a new memory repository. If path is given, records are persisted into JSON file.
+func NewMemoryRepository(path ...string) *MemoryRepository {
+	repo := &MemoryRepository{}
+	for _, repo.Path = range path {
+	}
+	return repo
+}
+
+func (this *MemoryRepository) load(res *Resource) (err error) {
+	if res.TenantScope != nil {
+		return errwrap.Wrap(ErrUnsupportedRepository, "Resource %q: memory repository with tenant scope", res.UID)
+	}
+	if res.SoftDelete != nil {
+		return errwrap.Wrap(ErrUnsupportedRepository, "Resource %q: memory repository with soft delete", res.UID)
+	}
+	if this.loaded {
+		return
+	}
+	this.records = map[string]reflect.Value{}
+	if this.Path != "" {
+		var data []byte
+		if data, err = ioutil.ReadFile(this.Path); err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+				this.loaded = true
+			}
+			return
+		}
+		slice := reflect.New(reflect.SliceOf(reflect.PtrTo(reflect.Indirect(reflect.ValueOf(res.Value)).Type())))
+		if err = json.Unmarshal(data, slice.Interface()); err != nil {
+			return fmt.Errorf("resource %q: load %q: %v", res.UID, this.Path, err)
+		}
+		for i := 0; i < slice.Elem().Len(); i++ {
+			record := slice.Elem().Index(i)
+			this.records[res.GetKey(record.Interface()).String()] = record
+		}
+	}
+	this.loaded = true
+	return
+}
+
+func (this *MemoryRepository) save(res *Resource) (err error) {
+	if this.Path == "" {
+		return
+	}
+	var (
+		records = make([]interface{}, 0, len(this.records))
+		data    []byte
+	)
+	for _, key := range this.keys() {
+		records = append(records, this.records[key].Interface())
+	}
+	if data, err = json.MarshalIndent(records, "", "  "); err != nil {
+		return
+	}
+	tmp := filepath.Join(filepath.Dir(this.Path), "."+filepath.Base(this.Path)+".tmp")
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	return os.Rename(tmp, this.Path)
+}
+
+// keys returns the sorted keys. Numeric keys are sorted by value.
+func (this *MemoryRepository) keys() (keys []string) {
+	for key := range this.records {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.ParseInt(keys[i], 10, 64)
+		b, errB := strconv.ParseInt(keys[j], 10, 64)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return
+}
+
+// copy returns a shallow copy of record pointer value
+func (this *MemoryRepository) copy(record interface{}) reflect.Value {
+	value := reflect.Indirect(reflect.ValueOf(record))
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	return ptr
+}
+
+func (this *MemoryRepository) FindOne(ctx *RepositoryContext, result interface{}, id aorm.ID) (err error) {
+	res := ctx.CRUD.res.GetResource()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(res); err != nil {
+		return
+	}
+	var record reflect.Value
+	if id == nil {
+		if keys := this.keys(); len(keys) > 0 {
+			record = this.records[keys[0]]
+		}
+	} else {
+		record = this.records[id.String()]
+	}
+	if !record.IsValid() {
+		return aorm.ErrRecordNotFound
+	}
+	reflect.ValueOf(result).Elem().Set(record.Elem())
+	return
+}
+
+func (this *MemoryRepository) FindMany(ctx *RepositoryContext, result interface{}) (err error) {
+	res := ctx.CRUD.res.GetResource()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(res); err != nil {
+		return
+	}
+	var (
+		slice = reflect.ValueOf(result).Elem()
+		ptr   = slice.Type().Elem().Kind() == reflect.Ptr
+		keys  = this.keys()
+	)
+	if res.DefaultPrimaryKeyOrder() == aorm.DESC {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+	items := reflect.MakeSlice(slice.Type(), 0, len(keys))
+	for _, key := range keys {
+		record := this.copy(this.records[key].Interface())
+		if ptr {
+			items = reflect.Append(items, record)
+		} else {
+			items = reflect.Append(items, record.Elem())
+		}
+	}
+	slice.Set(items)
+	return
+}
+
+func (this *MemoryRepository) Count(ctx *RepositoryContext, result interface{}) (err error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(ctx.CRUD.res.GetResource()); err != nil {
+		return
+	}
+	value := reflect.ValueOf(result).Elem()
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(len(this.records)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(len(this.records)))
+	default:
+		return fmt.Errorf("resource: count into %s is not supported", value.Type())
+	}
+	return
+}
+
+// Create stores the record. If key is zero and resource has a single integer primary field, the key is set to
+// max key + 1.
+func (this *MemoryRepository) Create(ctx *RepositoryContext, record interface{}) (err error) {
+	res := ctx.CRUD.res.GetResource()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(res); err != nil {
+		return
+	}
+	if key := res.GetKey(record); key == nil || key.IsZero() {
+		if err = this.autoIncrement(res, record); err != nil {
+			return
+		}
+	}
+	key := res.GetKey(record).String()
+	if _, ok := this.records[key]; ok {
+		return fmt.Errorf("resource %q: duplicate key %q", res.UID, key)
+	}
+	this.records[key] = this.copy(record)
+	return this.save(res)
+}
+
+func (this *MemoryRepository) autoIncrement(res *Resource, record interface{}) error {
+	if len(res.PrimaryFields) != 1 {
+		return fmt.Errorf("resource %q: key is zero", res.UID)
+	}
+	field := reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(res.PrimaryFields[0].StructIndex)
+	var max int64
+	for key := range this.records {
+		if v, err := strconv.ParseInt(key, 10, 64); err == nil && v > max {
+			max = v
+		}
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(max + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(max + 1))
+	default:
+		return fmt.Errorf("resource %q: key is zero", res.UID)
+	}
+	return nil
+}
+
+func (this *MemoryRepository) Update(ctx *RepositoryContext, record interface{}) (err error) {
+	res := ctx.CRUD.res.GetResource()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(res); err != nil {
+		return
+	}
+	key := res.GetKey(record).String()
+	if _, ok := this.records[key]; !ok {
+		return aorm.ErrRecordNotFound
+	}
+	this.records[key] = this.copy(record)
+	return this.save(res)
+}
+
+func (this *MemoryRepository) Delete(ctx *RepositoryContext, record interface{}) (err error) {
+	res := ctx.CRUD.res.GetResource()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err = this.load(res); err != nil {
+		return
+	}
+	key := res.GetKey(record).String()
+	if _, ok := this.records[key]; !ok {
+		return aorm.ErrRecordNotFound
+	}
+	delete(this.records, key)
+	return this.save(res)
+}
@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+)
+
+func memoryResource(t *testing.T, records ...*tenantItem) *Resource {
+	res := New(&tenantItem{}, "", "", nil)
+	res.SetRepository(NewMemoryRepository())
+	for _, record := range records {
+		if err := res.Crud(core.NewContext()).CallCreate(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	res := memoryResource(t, &tenantItem{ID: 1, Name: "a"})
+
+	ctx := core.NewContext()
+	ctx.ResourceID = res.GetKey(&tenantItem{ID: 2})
+	if err := res.Crud(ctx).CallDelete(&tenantItem{}); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+
+	ctx = core.NewContext()
+	ctx.ResourceID = res.GetKey(&tenantItem{ID: 1})
+	if err := res.Crud(ctx).CallDelete(&tenantItem{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := res.Crud(core.NewContext()).FindOne(&tenantItem{}, ctx.ResourceID); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("expected deleted record, but got %v", err)
+	}
+}
+
+func TestUnsupportedRepository(t *testing.T) {
+	res := memoryResource(t)
+	if err := res.SetSearchable("Name"); err != nil {
+		t.Fatal(err)
+	}
+	crud := res.Crud(core.NewContext())
+
+	cases := map[string]error{
+		"Stream":     crud.Stream(context.Background(), func(record interface{}) error { return nil }),
+		"DeleteMany": crud.CallDeleteMany(res.GetKey(&tenantItem{ID: 1})),
+	}
+	_, cases["Search"] = crud.Search(&SearchQuery{Text: "a"}, &[]*tenantItem{})
+
+	for name, err := range cases {
+		if err == nil || !strings.Contains(err.Error(), ErrUnsupportedRepository.Error()) {
+			t.Errorf("%s: expected %v, but got %v", name, ErrUnsupportedRepository, err)
+		}
+	}
+}
+
+func TestValidateUniqueRecords(t *testing.T) {
+	var (
+		res   = memoryResource(t, &tenantItem{ID: 1, Name: "a"}, &tenantItem{ID: 2, Name: "b"})
+		field = res.ModelStruct.FieldsByName["Name"]
+	)
+	cases := []struct {
+		record *tenantItem
+		value  string
+		want   bool
+	}{
+		{&tenantItem{}, "a", false},
+		{&tenantItem{}, "c", true},
+		{&tenantItem{ID: 1}, "a", true},
+		{&tenantItem{ID: 2}, "a", false},
+	}
+	for i, c := range cases {
+		ok, err := validateUniqueRecords(core.NewContext(), res, c.record, field, c.value)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if ok != c.want {
+			t.Errorf("#%d: expected %v, but got %v", i, c.want, ok)
+		}
+	}
+}
+
+func TestMemoryRepositoryDBScoped(t *testing.T) {
+	tenant := memoryResource(t)
+	if err := tenant.SetTenantScoped(); err != nil {
+		t.Fatal(err)
+	}
+	soft := New(&softItem{}, "", "", nil)
+	soft.SetRepository(NewMemoryRepository())
+	if err := soft.SetSoftDelete(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]error{
+		"tenant scope": tenant.Crud(core.NewContext()).Create(&tenantItem{Name: "a"}),
+		"soft delete":  soft.Crud(core.NewContext()).Create(&softItem{Name: "a"}),
+	}
+	for name, err := range cases {
+		if err == nil || !strings.Contains(err.Error(), ErrUnsupportedRepository.Error()) {
+			t.Errorf("%s: expected %v, but got %v", name, ErrUnsupportedRepository, err)
+		}
+	}
+}
+
+func TestAormRepositoryUpdateNotFound(t *testing.T) {
+	var (
+		db   = testDB(t, &tenantItem{})
+		res  = New(&tenantItem{}, "", "", nil)
+		crud = res.Crud(testContext(db))
+	)
+	if err := crud.Update(&tenantItem{ID: 1, Name: "a"}); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+	var count int
+	if err := crud.Count(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("update must not create the record, but got %d records", count)
+	}
+}
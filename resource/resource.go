@@ -54,6 +54,7 @@ type Resource struct {
 	PrimaryFields      []*aorm.StructField
 	Permission         *roles.Permission
 	Validators         []func(interface{}, *MetaValues, *core.Context) error
+	AsyncValidators    []*AsyncValidator
 	Processors         []func(interface{}, *MetaValues, *core.Context) error
 	newStructCallbacks []func(obj interface{}, site *core.Site)
 	ModelStruct        *aorm.ModelStruct
@@ -70,6 +71,13 @@ type Resource struct {
 	defaultDenyMode        func() bool
 	Tags                   aorm.TagSetting
 	defaultPrimaryKeyOrder aorm.Order
+	TenantScope            *TenantScope
+	OptimisticLock         *OptimisticLock
+	SoftDelete             *SoftDelete
+	PageOrder              []PageOrder
+	Searchable             *Searchable
+	Cache                  *ResourceCache
+	Repository             RecordRepository
 }
 
 // New initialize qor resource
@@ -210,6 +218,8 @@ func (res *Resource) GetKey(value interface{}) aorm.ID {
 }
 
 func (res *Resource) Validate(record interface{}, values *MetaValues, ctx *core.Context, onError func(err error) (stop bool)) {
+	var async []*asyncValidation
+
 	if values.IsRequirementCheck() {
 		var hasBlank bool
 
@@ -231,6 +241,18 @@ func (res *Resource) Validate(record interface{}, values *MetaValues, ctx *core.
 						}
 					}
 				}
+				if ruler, ok := value.Meta.(MetaValidationRuler); ok {
+					for _, err := range ValidateRules(ctx, res, record, values, value, ruler.GetValidationRules()) {
+						if onError(err) {
+							return
+						}
+					}
+				}
+				if av, ok := value.Meta.(MetaAsyncValidatorer); ok {
+					for _, vldr := range av.AsyncValidators() {
+						async = append(async, &asyncValidation{validator: vldr, value: value})
+					}
+				}
 			}
 		}
 		if hasBlank {
@@ -245,7 +267,11 @@ func (res *Resource) Validate(record interface{}, values *MetaValues, ctx *core.
 			}
 		}
 	}
-	return
+
+	for _, vldr := range res.AsyncValidators {
+		async = append(async, &asyncValidation{validator: vldr})
+	}
+	validateAsync(record, values, ctx, async, onError)
 }
 
 func (res *Resource) IsSingleton() bool {
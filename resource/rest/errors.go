@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+	"github.com/ecletus/core/resource/render"
+	"github.com/ecletus/roles"
+	"github.com/ecletus/validations"
+	"github.com/go-aorm/aorm"
+)
+
+// FieldError is the error of record field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is the JSON error response body
+type Error struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// ErrorOf converts err to JSON error. Validation errors are reported by field. The message of internal errors is
+// not exposed.
+func ErrorOf(err error) *Error {
+	var (
+		e    = &Error{Status: http.StatusInternalServerError}
+		errs = flatten(err)
+	)
+	for _, err := range errs {
+		var verr *validations.Error
+		if errors.As(err, &verr) {
+			e.Status = http.StatusUnprocessableEntity
+			e.Fields = append(e.Fields, FieldError{verr.Column, verr.Message})
+		}
+	}
+	if e.Status == http.StatusUnprocessableEntity {
+		e.Message = http.StatusText(e.Status)
+		return e
+	}
+
+	for _, err := range errs {
+		if status := statusOf(err); status != 0 {
+			e.Status = status
+			e.Message = err.Error()
+			return e
+		}
+	}
+	e.Message = http.StatusText(e.Status)
+	return e
+}
+
+func statusOf(err error) int {
+	var (
+		conflict *resource.ConflictError
+		query    *resource.QueryError
+		dup      resource.DuplicateUniqueIndexError
+	)
+	switch {
+	case errors.As(err, &conflict), errors.As(err, &dup):
+		return http.StatusConflict
+	case errors.As(err, &query):
+		return http.StatusBadRequest
+	case errors.Is(err, render.ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, roles.ErrPermissionDenied):
+		return http.StatusForbidden
+	case aorm.IsRecordNotFoundError(err):
+		return http.StatusNotFound
+	}
+	return 0
+}
+
+// flatten expands core.Errors and the causes of wrapped errors (errwrap and `Cause() error` implementors), so
+// errors.As and errors.Is reach the original errors.
+func flatten(err error) (errs []error) {
+	switch e := err.(type) {
+	case nil:
+		return
+	case core.Errors:
+		for _, err := range e {
+			errs = append(errs, flatten(err)...)
+		}
+		return
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			errs = append(errs, flatten(err)...)
+		}
+		return
+	}
+	errs = append(errs, err)
+	var cause error
+	switch e := err.(type) {
+	case interface{ Cause() error }:
+		cause = e.Cause()
+	case interface{ Prev() error }:
+		cause = e.Prev()
+	default:
+		cause = errors.Unwrap(err)
+	}
+	if cause != nil && cause != err {
+		errs = append(errs, flatten(cause)...)
+	}
+	return
+}
+
+// WriteError writes err as JSON error response
+func WriteError(w http.ResponseWriter, err error) {
+	e := ErrorOf(err)
+	if e.Status == http.StatusInternalServerError {
+		log.Errorf("%v", err)
+	}
+	writeJSON(w, e.Status, map[string]interface{}{"error": e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if value != nil {
+		json.NewEncoder(w).Encode(value)
+	}
+}
+
+var typeOfError = reflect.TypeOf(Error{})
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,246 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+	"github.com/ecletus/core/resource/render"
+	"github.com/go-aorm/aorm"
+)
+
+const (
+	// LayoutParam is the query param of layout name
+	LayoutParam = "layout"
+	// PageSizeParam is the query param of keyset page size
+	PageSizeParam = "page[size]"
+	// PageCursorParam is the query param of keyset page cursor
+	PageCursorParam = "page[cursor]"
+)
+
+// Handler is the REST/JSON handler of resource:
+//
+//	GET    /      FindManyLayout, filtered by `filter[...]` and `sort` params and paginated by `page[size]` and `page[cursor]`
+//	GET    /{id}  FindOneLayout
+//	POST   /      Decode and Create
+//	PUT    /{id}  Decode and Update
+//	PATCH  /{id}  Decode and Update
+//	DELETE /{id}  Delete
+//
+// The layout is selected by `layout` query param, or DefaultLayout. The results are JSON documents, or are serialized
+// by the renderer negotiated from `Accept` header (see render package).
+type Handler struct {
+	Resource      resource.Resourcer
+	Site          *core.Site
+	DefaultLayout string
+	pattern       string
+}
+
+// New creates a new handler of res
+func New(res resource.Resourcer) *Handler {
+	return &Handler{Resource: res, DefaultLayout: resource.DEFAULT_LAYOUT}
+}
+
+// Mount mounts the handler into site mux at pattern
+func (this *Handler) Mount(site *core.Site, pattern string) {
+	this.Site = site
+	this.pattern = strings.TrimSuffix(pattern, "/")
+	site.Mux.Mount(this.pattern, this)
+}
+
+// rawID returns the path segments after mount pattern. The site may be mounted at a prefix, so the pattern
+// segments are searched into path segments, or the mux may strip it, so the whole path is used if not found.
+func (this *Handler) rawID(r *http.Request) string {
+	var (
+		path    = splitPath(r.URL.Path)
+		pattern = splitPath(this.pattern)
+	)
+	for i := 0; i+len(pattern) <= len(path); i++ {
+		if equalSegments(path[i:i+len(pattern)], pattern) {
+			return strings.Join(path[i+len(pattern):], "/")
+		}
+	}
+	return strings.Join(path, "/")
+}
+
+func splitPath(path string) (segments []string) {
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return
+}
+
+func equalSegments(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *Handler) context(r *http.Request) *core.Context {
+	ctx := core.ContextFromRequest(r)
+	if ctx == nil {
+		ctx = this.Site.NewContext()
+		ctx.Request = r
+	}
+	return ctx.Clone()
+}
+
+func (this *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = this.context(r)
+		rawID  = this.rawID(r)
+		id     aorm.ID
+		err    error
+		layout = r.URL.Query().Get(LayoutParam)
+	)
+
+	if layout == "" {
+		layout = this.DefaultLayout
+	}
+
+	if rawID != "" {
+		if id, err = this.Resource.ParseID(rawID); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": &Error{Status: http.StatusNotFound, Message: err.Error()}})
+			return
+		}
+		ctx.ResourceID = id
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == nil:
+		err = this.index(w, r, ctx, layout)
+	case r.Method == http.MethodGet:
+		err = this.show(w, ctx, id, layout, http.StatusOK)
+	case r.Method == http.MethodPost && id == nil:
+		err = this.create(w, ctx, layout)
+	case (r.Method == http.MethodPut || r.Method == http.MethodPatch) && id != nil:
+		err = this.update(w, ctx, id, layout)
+	case r.Method == http.MethodDelete && id != nil:
+		err = this.delete(w, ctx, id)
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT, PATCH, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": &Error{
+			Status:  http.StatusMethodNotAllowed,
+			Message: http.StatusText(http.StatusMethodNotAllowed),
+		}})
+		return
+	}
+
+	if err != nil {
+		WriteError(w, err)
+	}
+}
+
+func (this *Handler) index(w http.ResponseWriter, r *http.Request, ctx *core.Context, layout string) (err error) {
+	var (
+		crud   = this.Resource.Crud(ctx)
+		query  = r.URL.Query()
+		page   *resource.Page
+		result interface{}
+	)
+	if crud, err = crud.Query(query); err != nil {
+		return
+	}
+	if size := query.Get(PageSizeParam); size != "" {
+		page = &resource.Page{Cursor: query.Get(PageCursorParam)}
+		if page.Size, err = strconv.Atoi(size); err != nil {
+			return &resource.QueryError{Param: PageSizeParam, Err: err}
+		}
+		crud = crud.Page(page)
+	}
+	if result, err = crud.SetLayoutOrDefault(layout).FindManyLayout(); err != nil {
+		return
+	}
+	body := map[string]interface{}{"data": result}
+	if page != nil {
+		body["next"], body["prev"] = page.Next, page.Prev
+	}
+	return this.write(w, ctx, http.StatusOK, result, body)
+}
+
+func (this *Handler) show(w http.ResponseWriter, ctx *core.Context, id aorm.ID, layout string, status int) (err error) {
+	var result interface{}
+	if result, err = this.Resource.Crud(ctx).SetLayoutOrDefault(layout).FindOneLayout(id); err != nil {
+		return
+	}
+	return this.write(w, ctx, status, result, map[string]interface{}{"data": result})
+}
+
+// negotiate returns the media type negotiated from `Accept` header, or render.ErrNotAcceptable
+func (this *Handler) negotiate(ctx *core.Context) (mediaType string, err error) {
+	var renderer render.Renderer
+	if mediaType, renderer = render.Negotiate(this.Resource, ctx.Request.Header.Get("Accept")); renderer == nil {
+		err = render.ErrNotAcceptable
+	}
+	return
+}
+
+// write writes body as JSON if the negotiated media type is JSON, otherwise renders result
+func (this *Handler) write(w http.ResponseWriter, ctx *core.Context, status int, result interface{}, body interface{}) error {
+	mediaType, err := this.negotiate(ctx)
+	if err != nil {
+		return err
+	}
+	if mediaType == "application/json" {
+		writeJSON(w, status, body)
+		return nil
+	}
+	return render.Write(w, status, &render.Rendering{Context: ctx, Resource: this.Resource, Result: result})
+}
+
+func (this *Handler) create(w http.ResponseWriter, ctx *core.Context, layout string) (err error) {
+	// negotiate before the write, so a not acceptable request does not change the record
+	if _, err = this.negotiate(ctx); err != nil {
+		return
+	}
+	record := this.Resource.NewStruct(ctx.Site)
+	if err = resource.Decode(ctx, record, this.Resource); err != nil {
+		return
+	}
+	if err = this.Resource.Crud(ctx).Create(record); err != nil {
+		return
+	}
+	return this.show(w, ctx, this.Resource.GetKey(record), layout, http.StatusCreated)
+}
+
+func (this *Handler) update(w http.ResponseWriter, ctx *core.Context, id aorm.ID, layout string) (err error) {
+	if _, err = this.negotiate(ctx); err != nil {
+		return
+	}
+	var (
+		crud   = this.Resource.Crud(ctx)
+		record = this.Resource.NewStruct(ctx.Site)
+	)
+	if err = crud.FindOne(record, id); err != nil {
+		return
+	}
+	if err = resource.Decode(ctx, record, this.Resource); err != nil {
+		return
+	}
+	if err = crud.Update(record); err != nil {
+		return
+	}
+	return this.show(w, ctx, id, layout, http.StatusOK)
+}
+
+func (this *Handler) delete(w http.ResponseWriter, ctx *core.Context, id aorm.ID) (err error) {
+	var (
+		crud   = this.Resource.Crud(ctx)
+		record = this.Resource.NewStruct(ctx.Site)
+	)
+	if err = crud.FindOne(record, id); err != nil {
+		return
+	}
+	if err = crud.Delete(record); err != nil {
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+	return
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+	"github.com/ecletus/core/resource/render"
+	"github.com/ecletus/roles"
+	"github.com/ecletus/validations"
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+func TestRawID(t *testing.T) {
+	cases := []struct {
+		pattern, path, id string
+	}{
+		{"/users", "/users", ""},
+		{"/users", "/users/5", "5"},
+		{"/users", "/site/users/5/", "5"},
+		{"/users", "/superusers/5", "superusers/5"},
+		{"/users", "/users-archive/users/5", "5"},
+		{"/users", "/5", "5"},
+		{"", "/5", "5"},
+	}
+	for _, c := range cases {
+		h := &Handler{pattern: c.pattern}
+		if id := h.rawID(httptest.NewRequest(http.MethodGet, c.path, nil)); id != c.id {
+			t.Errorf("%s at %q: expected %q, but got %q", c.path, c.pattern, c.id, id)
+		}
+	}
+}
+
+func TestErrorOf(t *testing.T) {
+	var (
+		internal = errors.New("pq: password authentication failed")
+		invalid  = &validations.Error{Column: "Name", Message: "is blank"}
+	)
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"internal", internal, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)},
+		{"wrapped internal", errwrap.Wrap(internal, "save"), http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)},
+		{"permission", errwrap.Wrap(roles.ErrPermissionDenied, "update"), http.StatusForbidden, ""},
+		{"not found", core.Errors{errwrap.Wrap(aorm.ErrRecordNotFound, "find")}, http.StatusNotFound, ""},
+		{"query", errwrap.Wrap(&resource.QueryError{Param: "sort", Err: internal}, "query"), http.StatusBadRequest, ""},
+		{"validation", core.Errors{errwrap.Wrap(invalid, "validate")}, http.StatusUnprocessableEntity, http.StatusText(http.StatusUnprocessableEntity)},
+	}
+	for _, c := range cases {
+		e := ErrorOf(c.err)
+		if e.Status != c.status {
+			t.Errorf("%s: expected status %d, but got %d", c.name, c.status, e.Status)
+		}
+		if c.message != "" && e.Message != c.message {
+			t.Errorf("%s: expected message %q, but got %q", c.name, c.message, e.Message)
+		}
+	}
+	if e := ErrorOf(core.Errors{invalid}); len(e.Fields) != 1 || e.Fields[0].Field != "Name" {
+		t.Errorf("expected field error of Name, but got %v", e.Fields)
+	}
+}
+
+func TestNegotiateBeforeWrite(t *testing.T) {
+	var (
+		h   = &Handler{}
+		ctx = core.NewContext()
+	)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	ctx.Request.Header.Set("Accept", "application/x-unknown")
+
+	for name, write := range map[string]func(w http.ResponseWriter) error{
+		"create": func(w http.ResponseWriter) error { return h.create(w, ctx, "") },
+		"update": func(w http.ResponseWriter) error { return h.update(w, ctx, nil, "") },
+	} {
+		w := httptest.NewRecorder()
+		if err := write(w); err != render.ErrNotAcceptable {
+			t.Errorf("%s: expected %v, but got %v", name, render.ErrNotAcceptable, err)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package rest
+
+import (
+	path_helpers "github.com/moisespsena-go/path-helpers"
+	"github.com/op/go-logging"
+)
+
+var log = logging.MustGetLogger(path_helpers.GetCalledDir())
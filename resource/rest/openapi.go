@@ -0,0 +1,238 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/roles"
+)
+
+// OpenAPIVersion is the OpenAPI specification version of generated documents
+const OpenAPIVersion = "3.0.3"
+
+// Document is the OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem is the operations of path by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// OpenAPI generates the OpenAPI document of mounted handlers
+type OpenAPI struct {
+	Title    string
+	Version  string
+	Handlers []*Handler
+}
+
+// NewOpenAPI creates a new OpenAPI generator
+func NewOpenAPI(title, version string, handlers ...*Handler) *OpenAPI {
+	return &OpenAPI{Title: title, Version: version, Handlers: handlers}
+}
+
+// Add adds handlers to document
+func (this *OpenAPI) Add(handlers ...*Handler) {
+	this.Handlers = append(this.Handlers, handlers...)
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {schema}}
+}
+
+func dataSchema(data *Schema) *Schema {
+	return &Schema{Type: "object", Properties: map[string]*Schema{"data": data}}
+}
+
+// Document generates the document of handlers mounted into context site. Schemas are generated with context
+// metas and permissions.
+func (this *OpenAPI) Document(ctx *core.Context) *Document {
+	doc := &Document{
+		OpenAPI:    OpenAPIVersion,
+		Info:       Info{this.Title, this.Version},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+
+	errorSchema := TypeSchema(typeOfError)
+	doc.Components.Schemas["Error"] = &Schema{Type: "object", Properties: map[string]*Schema{"error": errorSchema}}
+
+	for _, h := range this.Handlers {
+		if ctx.Site != nil && h.Site != nil && h.Site != ctx.Site {
+			continue
+		}
+		this.addHandler(ctx, doc, h)
+	}
+	return doc
+}
+
+func errorResponses(responses map[string]*Response, statuses ...int) map[string]*Response {
+	for _, status := range statuses {
+		responses[statusKey(status)] = &Response{Description: http.StatusText(status), Content: jsonContent(ref("Error"))}
+	}
+	return responses
+}
+
+func (this *OpenAPI) addHandler(ctx *core.Context, doc *Document, h *Handler) {
+	var (
+		res         = h.Resource
+		name        = res.GetResource().ID
+		tags        = []string{name}
+		layoutNames []interface{}
+		names       []string
+	)
+
+	for layoutName := range res.GetResource().Layouts {
+		names = append(names, layoutName)
+	}
+	sort.Strings(names)
+	for _, layoutName := range names {
+		layoutNames = append(layoutNames, layoutName)
+		schemaName := name
+		if layoutName != h.DefaultLayout {
+			schemaName += "." + layoutName
+		}
+		doc.Components.Schemas[schemaName] = LayoutSchema(ctx, res, res.GetLayout(layoutName))
+	}
+	if _, ok := doc.Components.Schemas[name]; !ok {
+		doc.Components.Schemas[name] = ModelSchema(ctx, res)
+	}
+	doc.Components.Schemas[name+"CreateInput"] = InputSchema(ctx, res, roles.Create)
+	doc.Components.Schemas[name+"UpdateInput"] = InputSchema(ctx, res, roles.Update)
+
+	var (
+		layoutParam = &Parameter{Name: LayoutParam, In: "query", Description: "layout name",
+			Schema: &Schema{Type: "string", Enum: layoutNames}}
+		idParam = &Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		item    = dataSchema(ref(name))
+		list    = &Schema{Type: "object", Properties: map[string]*Schema{
+			"data": {Type: "array", Items: ref(name)},
+			"next": {Type: "string"},
+			"prev": {Type: "string"},
+		}}
+	)
+
+	collection := PathItem{
+		"get": {
+			OperationID: "list" + name,
+			Tags:        tags,
+			Parameters: []*Parameter{
+				layoutParam,
+				{Name: "filter", In: "query", Style: "deepObject",
+					Description: "`filter[field][operator]=value` conditions, where operator defaults to `eq`",
+					Schema:      &Schema{Type: "object", AdditionalProperties: &Schema{Type: "object"}}},
+				{Name: "sort", In: "query", Description: "comma separated fields, prefixed by `-` to descending order",
+					Schema: &Schema{Type: "string"}},
+				{Name: PageSizeParam, In: "query", Schema: &Schema{Type: "integer"}},
+				{Name: PageCursorParam, In: "query", Schema: &Schema{Type: "string"}},
+			},
+			Responses: errorResponses(map[string]*Response{
+				"200": {Description: "OK", Content: jsonContent(list)},
+			}, http.StatusBadRequest, http.StatusForbidden),
+		},
+		"post": {
+			OperationID: "create" + name,
+			Tags:        tags,
+			Parameters:  []*Parameter{layoutParam},
+			RequestBody: &RequestBody{Required: true, Content: jsonContent(ref(name + "CreateInput"))},
+			Responses: errorResponses(map[string]*Response{
+				"201": {Description: "Created", Content: jsonContent(item)},
+			}, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity),
+		},
+	}
+
+	update := &Operation{
+		OperationID: "update" + name,
+		Tags:        tags,
+		Parameters:  []*Parameter{idParam, layoutParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref(name + "UpdateInput"))},
+		Responses: errorResponses(map[string]*Response{
+			"200": {Description: "OK", Content: jsonContent(item)},
+		}, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	}
+	patch := *update
+	patch.OperationID = "patch" + name
+
+	member := PathItem{
+		"get": {
+			OperationID: "get" + name,
+			Tags:        tags,
+			Parameters:  []*Parameter{idParam, layoutParam},
+			Responses: errorResponses(map[string]*Response{
+				"200": {Description: "OK", Content: jsonContent(item)},
+			}, http.StatusForbidden, http.StatusNotFound),
+		},
+		"put":   update,
+		"patch": &patch,
+		"delete": {
+			OperationID: "delete" + name,
+			Tags:        tags,
+			Parameters:  []*Parameter{idParam},
+			Responses: errorResponses(map[string]*Response{
+				"204": {Description: "No Content"},
+			}, http.StatusForbidden, http.StatusNotFound),
+		},
+	}
+
+	doc.Paths[h.pattern] = &collection
+	doc.Paths[h.pattern+"/{id}"] = &member
+}
+
+// ServeHTTP writes the document of request context as JSON
+func (this *OpenAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := core.ContextFromRequest(r)
+	if ctx == nil {
+		ctx = core.NewContext()
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(this.Document(ctx))
+}
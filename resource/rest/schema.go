@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/resource"
+	"github.com/ecletus/core/utils"
+	"github.com/ecletus/roles"
+)
+
+// Enumer is implemented by field types with enumerated values
+type Enumer = resource.Enumer
+
+// Schema is the OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// FromJSONSchema converts the JSON Schema generated by resource package to OpenAPI 3.0 schema. Type unions with
+// `null` are nullable and `$defs` references are component references.
+func FromJSONSchema(js *resource.JSONSchema) *Schema {
+	if js == nil {
+		return nil
+	}
+	schema := &Schema{
+		Title:       js.Title,
+		Description: js.Description,
+		Format:      js.Format,
+		Required:    js.Required,
+		Enum:        js.Enum,
+		Minimum:     js.Minimum,
+		Items:       FromJSONSchema(js.Items),
+	}
+	if js.Ref != "" {
+		schema.Ref = strings.Replace(js.Ref, "#/$defs/", "#/components/schemas/", 1)
+	}
+	switch t := js.Type.(type) {
+	case string:
+		schema.Type = t
+	case []string:
+		for _, t := range t {
+			if t == "null" {
+				schema.Nullable = true
+			} else {
+				schema.Type = t
+			}
+		}
+	}
+	if js.ContentEncoding == "base64" {
+		schema.Format = "byte"
+	}
+	if js.AdditionalProperties != nil {
+		schema.AdditionalProperties = FromJSONSchema(js.AdditionalProperties)
+	}
+	if js.Properties != nil {
+		schema.Properties = make(map[string]*Schema, len(js.Properties))
+		for name, prop := range js.Properties {
+			schema.Properties[name] = FromJSONSchema(prop)
+		}
+	}
+	return schema
+}
+
+// TypeSchema returns the schema of type. See resource.TypeJSONSchema.
+func TypeSchema(typ reflect.Type) *Schema {
+	return FromJSONSchema(resource.TypeJSONSchema(typ))
+}
+
+// MetaSchema returns the schema of meta value. See resource.MetaJSONSchema.
+func MetaSchema(ctx *core.Context, meta resource.Metaor) *Schema {
+	return FromJSONSchema(resource.MetaJSONSchema(ctx, meta))
+}
+
+// LayoutSchema returns the output schema of resource layout. If the layout type is the resource model, returns the
+// ModelSchema, else the schema of layout type.
+func LayoutSchema(ctx *core.Context, res resource.Resourcer, layout resource.LayoutInterface) *Schema {
+	if layout != nil && layout.GetType() != nil {
+		if typ := utils.IndirectType(reflect.TypeOf(layout.GetType())); typ != res.GetModelStruct().Type {
+			return TypeSchema(typ)
+		}
+	}
+	return ModelSchema(ctx, res)
+}
+
+// ModelSchema returns the output schema of resource model described by the readable context metas
+func ModelSchema(ctx *core.Context, res resource.Resourcer) *Schema {
+	if _, ok := res.(*resource.Resource); ok {
+		// base resource does not have metas
+		return TypeSchema(res.GetModelStruct().Type)
+	}
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, meta := range res.GetContextMetas(ctx) {
+		field := meta.GetFieldStruct()
+		if field == nil || meta.HasPermission(roles.Read, ctx) == roles.DENY {
+			continue
+		}
+		name, omit := resource.JSONFieldName(field.Struct)
+		if omit {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = MetaSchema(ctx, meta)
+	}
+	return schema
+}
+
+// InputSchema returns the request body schema of settable metas with mode permission
+func InputSchema(ctx *core.Context, res resource.Resourcer, mode roles.PermissionMode) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if _, ok := res.(*resource.Resource); ok {
+		// base resource does not have metas
+		return schema
+	}
+	for _, meta := range res.GetContextMetas(ctx) {
+		if meta.GetSetter() == nil || meta.HasPermission(mode, ctx) == roles.DENY {
+			continue
+		}
+		if ro, ok := meta.(resource.ReadonlyMetaor); ok && ro.CanReadOnly() {
+			continue
+		}
+		schema.Properties[meta.GetName()] = MetaSchema(ctx, meta)
+		if meta.IsRequired() {
+			schema.Required = append(schema.Required, meta.GetName())
+		}
+	}
+	return schema
+}
@@ -26,19 +26,12 @@ func Decode(context *core.Context, result interface{}, res Resourcer, f ...Proce
 		prefix = DefaultFormInputPrefix
 	}
 
-	if strings.Contains(context.Request.Header.Get("Content-Type"), "json") {
-		metaValues, err = ConvertJSONToMetaValues(context, context.Request.Body, metaors)
-		context.Request.Body.Close()
-	} else {
-		metaValues, err = ConvertFormToMetaValues(context, context.Request, metaors, prefix+".")
-	}
-
-	if err != nil {
+	if metaValues, err = requestMetaValues(context, result, metaors, prefix+"."); err != nil {
 		return
 	}
 
 	var errors core.Errors
-	if len(metaors) > 0 && len(metaValues.Values) == 0 {
+	if len(metaors) > 0 && len(metaValues.Values) == 0 && !metaValues.Patch {
 		for _, metaor := range metaors {
 			if metaor.IsRequired() {
 				errors.AddError(ErrMetaCantBeBlank(context, metaor))
@@ -57,6 +50,41 @@ func Decode(context *core.Context, result interface{}, res Resourcer, f ...Proce
 	return nil
 }
 
+// requestMetaValues converts request body to meta values by content type
+func requestMetaValues(context *core.Context, result interface{}, metaors []Metaor, formPrefix string) (*MetaValues, error) {
+	ct := context.Request.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(ct, MergePatchContentType):
+		defer context.Request.Body.Close()
+		return ConvertJSONMergePatchToMetaValues(context, context.Request.Body, metaors)
+	case strings.Contains(ct, JSONPatchContentType):
+		defer context.Request.Body.Close()
+		return ConvertJSONPatchToMetaValues(context, context.Request.Body, result, metaors)
+	}
+	if decoder := BodyDecoders.Get(ct); decoder != nil {
+		defer context.Request.Body.Close()
+		values, err := decoder.DecodeBody(context.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		return convertMapToMetaValues(context, values, metaors, false)
+	} else if strings.Contains(ct, "json") && !strings.HasPrefix(ct, "multipart/") {
+		// unregistered or malformed JSON content type
+		defer context.Request.Body.Close()
+		values, err := JSONBodyDecoder.DecodeBody(context.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		return convertMapToMetaValues(context, values, metaors, false)
+	}
+	if ok, err := IsMultipartJSON(context.Request, MultipartMaxMemoryOf(context)); err != nil {
+		return nil, err
+	} else if ok {
+		return ConvertMultipartJSONToMetaValues(context, context.Request, metaors)
+	}
+	return ConvertFormToMetaValues(context, context.Request, metaors, formPrefix)
+}
+
 func DecodeMetaValues(context *core.Context, result interface{}, res Resourcer, prefix string, metaValues *MetaValues, f ...ProcessorFlag) (err error) {
 	var errors core.Errors
 	processor := DecodeToResource(res, result, &MetaValue{Name: prefix, MetaValues: metaValues}, context, f...)
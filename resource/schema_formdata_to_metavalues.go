@@ -18,7 +18,6 @@ func ConvertFormDataToMetaValues(context *core.Context, form url.Values, multipa
 	var (
 		sortedFormKeys []string
 		tree           = NewFormTree()
-		metaorsMap     = map[string]Metaor{}
 	)
 
 	if prefix != "" {
@@ -72,24 +71,30 @@ func ConvertFormDataToMetaValues(context *core.Context, form url.Values, multipa
 			}
 		}
 	}
+	return formTreeToMetaValues(context, tree, metaors, root[0])
+}
+
+// formTreeToMetaValues walks tree into meta values of root
+func formTreeToMetaValues(context *core.Context, tree *FormTree, metaors []Metaor, root *MetaValue) (_ *MetaValues, err error) {
+	var metaorsMap = map[string]Metaor{}
 	for _, metaor := range metaors {
 		metaorsMap[metaor.GetName()] = metaor
 	}
 
-	if root[0].MetaValues == nil {
-		root[0].MetaValues = &MetaValues{}
+	if root.MetaValues == nil {
+		root.MetaValues = &MetaValues{}
 	}
 
-	root[0].Index = -1
+	root.Index = -1
 	tree.Index = -1
 
 	if tree.Children.Map != nil {
-		err = FormTreeWalkMetaValues(tree, context, metaorsMap, root[0])
+		err = FormTreeWalkMetaValues(tree, context, metaorsMap, root)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return root[0].MetaValues, nil
+	return root.MetaValues, nil
 }
 
 // ConvertFormToMetaValues convert form to meta values
@@ -16,7 +16,7 @@ func ConvertJSONToMetaValues(context *core.Context, reader io.Reader, metaors []
 	)
 
 	if err = decoder.Decode(&values); err == nil {
-		return convertMapToMetaValues(context, values, metaors)
+		return convertMapToMetaValues(context, values, metaors, false)
 	}
 	return nil, err
 }
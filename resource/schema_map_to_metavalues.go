@@ -2,13 +2,15 @@ package resource
 
 import "github.com/ecletus/core"
 
-func convertMapToMetaValues(context *core.Context, values map[string]interface{}, metaors []Metaor, root ...*MetaValue) (*MetaValues, error) {
+// convertMapToMetaValues converts values to meta values. On patch mode, absent metas are not checked for requirement
+// and null values clears the fields.
+func convertMapToMetaValues(context *core.Context, values map[string]interface{}, metaors []Metaor, patch bool, root ...*MetaValue) (*MetaValues, error) {
 	if len(root) == 0 || root[0] == nil {
 		root = []*MetaValue{{}}
 	}
 	var (
 		parent       = root[0]
-		metaValues   = &MetaValues{}
+		metaValues   = &MetaValues{Patch: patch}
 		metaorMap    = make(map[string]Metaor)
 		childMetaors []Metaor
 		newMetaValue = func(key string, value interface{}) {
@@ -20,7 +22,7 @@ func convertMapToMetaValues(context *core.Context, values map[string]interface{}
 				if metaor != nil {
 					childMetaors = metaor.GetContextMetas(nil, context)
 				}
-				if children, err := convertMapToMetaValues(context, result, childMetaors, parent); err == nil {
+				if children, err := convertMapToMetaValues(context, result, childMetaors, patch, parent); err == nil {
 					metaValue = &MetaValue{Parent: parent, Name: key, Meta: metaor, MetaValues: children}
 				}
 			case []interface{}:
@@ -29,7 +31,7 @@ func convertMapToMetaValues(context *core.Context, values map[string]interface{}
 						if metaor != nil {
 							childMetaors = metaor.GetContextMetas(nil, context)
 						}
-						if children, err := convertMapToMetaValues(context, mr, childMetaors, parent); err == nil {
+						if children, err := convertMapToMetaValues(context, mr, childMetaors, patch, parent); err == nil {
 							metaValue := &MetaValue{
 								Parent:     parent,
 								Name:       key,
@@ -46,7 +48,7 @@ func convertMapToMetaValues(context *core.Context, values map[string]interface{}
 					}
 				}
 			default:
-				metaValue = &MetaValue{Parent: parent, Name: key, Value: value, Meta: metaor}
+				metaValue = &MetaValue{Parent: parent, Name: key, Value: value, Meta: metaor, Null: patch && value == nil}
 			}
 
 			if metaValue != nil {
@@ -69,3 +71,8 @@ func convertMapToMetaValues(context *core.Context, values map[string]interface{}
 
 	return metaValues, nil
 }
+
+// ConvertMapToMetaValues convert map to meta values
+func ConvertMapToMetaValues(context *core.Context, values map[string]interface{}, metaors []Metaor) (*MetaValues, error) {
+	return convertMapToMetaValues(context, values, metaors, false)
+}
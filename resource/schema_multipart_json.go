@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/utils"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+var (
+	// MultipartJSONPart is the name of multipart part with the JSON document
+	MultipartJSONPart = "json"
+	// MultipartFileRefKey is the key of JSON object referencing a file part, such as `{"$file": "avatar"}`
+	MultipartFileRefKey = "$file"
+	// MultipartMaxMemory is the default max memory of multipart form parsing. See SetMultipartMaxMemory.
+	MultipartMaxMemory int64 = 32 << 20
+)
+
+type multipartMaxMemoryKey struct{}
+
+// SetMultipartMaxMemory sets the max memory of multipart form parsing of context requests
+func SetMultipartMaxMemory(ctx *core.Context, maxMemory int64) {
+	ctx.SetValue(multipartMaxMemoryKey{}, maxMemory)
+}
+
+// MultipartMaxMemoryOf returns the max memory of multipart form parsing of context requests, or MultipartMaxMemory
+func MultipartMaxMemoryOf(ctx *core.Context) int64 {
+	if v, ok := ctx.Value(multipartMaxMemoryKey{}).(int64); ok && v > 0 {
+		return v
+	}
+	return MultipartMaxMemory
+}
+
+// IsMultipartJSON returns if request is a multipart form with the JSON part. The form is parsed with maxMemory if
+// not parsed yet; the parse error is returned, since the request body is consumed.
+func IsMultipartJSON(request *http.Request, maxMemory int64) (bool, error) {
+	if !strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/") {
+		return false, nil
+	}
+	if request.MultipartForm == nil {
+		if err := request.ParseMultipartForm(maxMemory); err != nil {
+			return false, errwrap.Wrap(err, "parse multipart form")
+		}
+	}
+	return len(request.MultipartForm.Value[MultipartJSONPart]) > 0 || len(request.MultipartForm.File[MultipartJSONPart]) > 0, nil
+}
+
+// ConvertMultipartJSONToMetaValues converts multipart request with the JSON part and file parts to meta values. File
+// parts are referenced from JSON by `{"$file": "part name"}`.
+func ConvertMultipartJSONToMetaValues(context *core.Context, request *http.Request, metaors []Metaor, root ...*MetaValue) (*MetaValues, error) {
+	if len(root) == 0 || root[0] == nil {
+		root = []*MetaValue{{}}
+	}
+	var (
+		form = request.MultipartForm
+		data []byte
+		doc  map[string]interface{}
+		err  error
+	)
+	if values := form.Value[MultipartJSONPart]; len(values) > 0 {
+		data = []byte(values[0])
+	} else {
+		var f multipart.File
+		if f, err = form.File[MultipartJSONPart][0].Open(); err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("multipart part %q: %v", MultipartJSONPart, err)
+	}
+
+	var (
+		values = map[string]interface{}{}
+		keys   []string
+	)
+	if err = multipartJSONFlatten(form, "", doc, values); err != nil {
+		return nil, err
+	}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	utils.SortFormKeys(keys)
+
+	tree := NewFormTree()
+	for _, key := range keys {
+		tree.Of(key).Value = values[key]
+	}
+	return formTreeToMetaValues(context, tree, metaors, root[0])
+}
+
+// multipartJSONFlatten flattens value into form keys, such as `Items.0.Name`. The root value must be an object of
+// fields, and the keys can't be blank or contain the form key separators (`.`, `[` and `]`).
+func multipartJSONFlatten(form *multipart.Form, key string, value interface{}, values map[string]interface{}) error {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+		return key + "." + name
+	}
+
+	if key == "" {
+		if t, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("the JSON value must be an object")
+		} else if _, ok = t[MultipartFileRefKey]; ok && len(t) == 1 {
+			return fmt.Errorf("the JSON value must be an object of fields, not a file reference")
+		}
+	}
+
+	switch t := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := t[MultipartFileRefKey]; ok && len(t) == 1 {
+			files, err := multipartJSONFiles(form, ref)
+			if err != nil {
+				return err
+			}
+			values[key] = files
+			return nil
+		}
+		for name, v := range t {
+			if name == "" || strings.ContainsAny(name, ".[]") {
+				return fmt.Errorf("invalid key %q", join(name))
+			}
+			if err := multipartJSONFlatten(form, join(name), v, values); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		var scalars []string
+		for i, v := range t {
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				if err := multipartJSONFlatten(form, join(fmt.Sprint(i)), v, values); err != nil {
+					return err
+				}
+			default:
+				scalars = append(scalars, multipartJSONString(v))
+			}
+		}
+		if len(scalars) > 0 || len(t) == 0 {
+			values[key] = scalars
+		}
+	default:
+		values[key] = []string{multipartJSONString(t)}
+	}
+	return nil
+}
+
+func multipartJSONString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func multipartJSONFiles(form *multipart.Form, ref interface{}) (files []*multipart.FileHeader, err error) {
+	var names []string
+	switch t := ref.(type) {
+	case string:
+		names = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				names = append(names, s)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid file reference %v", ref)
+	}
+	for _, name := range names {
+		headers := form.File[name]
+		if len(headers) == 0 {
+			return nil, fmt.Errorf("file part %q does not exist", name)
+		}
+		for _, h := range headers {
+			if h.Filename != "" {
+				files = append(files, h)
+			}
+		}
+	}
+	return
+}
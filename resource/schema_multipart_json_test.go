@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+)
+
+func multipartRequest(t *testing.T, parts map[string]string, truncate int) *http.Request {
+	var (
+		body bytes.Buffer
+		w    = multipart.NewWriter(&body)
+	)
+	for name, value := range parts {
+		if err := w.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	data := body.Bytes()
+	data = data[:len(data)-truncate]
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func TestIsMultipartJSON(t *testing.T) {
+	form := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	cases := []struct {
+		name    string
+		request *http.Request
+		ok      bool
+		err     bool
+	}{
+		{"json part", multipartRequest(t, map[string]string{MultipartJSONPart: `{"Name": "a"}`}, 0), true, false},
+		{"form parts", multipartRequest(t, map[string]string{"Name": "a"}, 0), false, false},
+		{"truncated", multipartRequest(t, map[string]string{MultipartJSONPart: `{"Name": "a"}`}, 10), false, true},
+		{"not multipart", form, false, false},
+	}
+	for _, c := range cases {
+		ok, err := IsMultipartJSON(c.request, MultipartMaxMemory)
+		if (err != nil) != c.err {
+			t.Errorf("%s: expected error %v, but got %v", c.name, c.err, err)
+		}
+		if ok != c.ok {
+			t.Errorf("%s: expected %v, but got %v", c.name, c.ok, ok)
+		}
+	}
+}
+
+func TestMultipartMaxMemoryOf(t *testing.T) {
+	ctx := core.NewContext()
+	if v := MultipartMaxMemoryOf(ctx); v != MultipartMaxMemory {
+		t.Errorf("expected default %d, but got %d", MultipartMaxMemory, v)
+	}
+	SetMultipartMaxMemory(ctx, 1<<10)
+	if v := MultipartMaxMemoryOf(ctx); v != 1<<10 {
+		t.Errorf("expected %d, but got %d", 1<<10, v)
+	}
+}
+
+func TestMultipartJSONFlatten(t *testing.T) {
+	cases := []struct {
+		doc  string
+		want map[string]interface{}
+		err  bool
+	}{
+		{`{"Name": "a", "Items": [{"Code": 1}], "Tags": ["x", "y"]}`,
+			map[string]interface{}{"Name": []string{"a"}, "Items.0.Code": []string{"1"}, "Tags": []string{"x", "y"}}, false},
+		{`{"": 1}`, nil, true},
+		{`{"a.": 1}`, nil, true},
+		{`{"a[0]": 1}`, nil, true},
+		{`{"Items": [{"b]": 1}]}`, nil, true},
+		{`{"$file": "x"}`, nil, true},
+		{`[1]`, nil, true},
+		{`1`, nil, true},
+	}
+	for _, c := range cases {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(c.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]interface{}{}
+		err := multipartJSONFlatten(nil, "", doc, values)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected error", c.doc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.doc, err)
+		} else if !reflect.DeepEqual(values, c.want) {
+			t.Errorf("%s: expected %v, but got %v", c.doc, c.want, values)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ecletus/core"
+)
+
+const (
+	// MergePatchContentType is the media type of JSON Merge Patch (RFC 7396)
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is the media type of JSON Patch (RFC 6902)
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// JSONPatchOperation is an operation of JSON Patch document
+type JSONPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// JSONPatchError is the error of invalid or failed JSON Patch operation
+type JSONPatchError struct {
+	Index int
+	Op    *JSONPatchOperation
+	Err   error
+}
+
+func (this *JSONPatchError) Error() string {
+	return fmt.Sprintf("json patch operation #%d %q %q: %v", this.Index, this.Op.Op, this.Op.Path, this.Err)
+}
+
+func (this *JSONPatchError) Cause() error {
+	return this.Err
+}
+
+// ConvertJSONMergePatchToMetaValues converts JSON Merge Patch to meta values. Only present keys are applied, null
+// clears the value and nested objects are patched recursively.
+func ConvertJSONMergePatchToMetaValues(context *core.Context, reader io.Reader, metaors []Metaor) (*MetaValues, error) {
+	var values = map[string]interface{}{}
+	if err := json.NewDecoder(reader).Decode(&values); err != nil {
+		return nil, err
+	}
+	return convertMapToMetaValues(context, values, metaors, true)
+}
+
+// ConvertJSONPatchToMetaValues converts JSON Patch operations to patch meta values. The `test`, `copy` and `move`
+// operations read the current values from record. Array index paths are not supported: arrays are replaced as a whole.
+func ConvertJSONPatchToMetaValues(context *core.Context, reader io.Reader, record interface{}, metaors []Metaor) (*MetaValues, error) {
+	var ops []*JSONPatchOperation
+	if err := json.NewDecoder(reader).Decode(&ops); err != nil {
+		return nil, err
+	}
+	values, err := JSONPatchToMergePatch(context, ops, record, metaors)
+	if err != nil {
+		return nil, err
+	}
+	return convertMapToMetaValues(context, values, metaors, true)
+}
+
+// JSONPatchToMergePatch converts JSON Patch operations to merge patch document. As RFC 6902 requires, `remove`,
+// `replace`, `move`, `copy` and `test` fail if their path (or `from`) does not exist, and `add` fails if the parent
+// of path does not exist.
+func JSONPatchToMergePatch(context *core.Context, ops []*JSONPatchOperation, record interface{}, metaors []Metaor) (doc map[string]interface{}, err error) {
+	var (
+		// removed are the pointers removed by previous operations. Their values are null into doc.
+		removed = map[string]bool{}
+
+		get = func(path []string) (interface{}, bool, error) {
+			for i := range path {
+				if removed[jsonPointer(path[:i+1])] {
+					return nil, false, nil
+				}
+			}
+			if v, ok := jsonPatchLookup(doc, path); ok {
+				return v, true, nil
+			}
+			return jsonPatchCurrentValue(context, record, metaors, path)
+		}
+		exists = func(path []string) (ok bool, err error) {
+			_, ok, err = get(path)
+			return
+		}
+		parentExists = func(path []string) (bool, error) {
+			if len(path) == 1 {
+				return true, nil
+			}
+			v, ok, err := get(path[:len(path)-1])
+			if err != nil || !ok {
+				return false, err
+			}
+			_, ok = v.(map[string]interface{})
+			return ok, nil
+		}
+		set = func(path []string, value interface{}) {
+			pointer := jsonPointer(path)
+			for p := range removed {
+				if p == pointer || strings.HasPrefix(p, pointer+"/") {
+					delete(removed, p)
+				}
+			}
+			jsonPatchSet(doc, path, value)
+		}
+		remove = func(path []string) {
+			removed[jsonPointer(path)] = true
+			jsonPatchSet(doc, path, nil)
+		}
+	)
+	doc = map[string]interface{}{}
+
+	for i, op := range ops {
+		opErr := func(err error) error {
+			return &JSONPatchError{i, op, err}
+		}
+		path, err := ParseJSONPointer(op.Path)
+		if err != nil {
+			return nil, opErr(err)
+		}
+		var ok bool
+		switch op.Op {
+		case "add":
+			if ok, err = parentExists(path); err != nil {
+				return nil, opErr(err)
+			} else if !ok {
+				return nil, opErr(fmt.Errorf("parent of path %q does not exist", op.Path))
+			}
+			set(path, op.Value)
+		case "replace", "remove":
+			if ok, err = exists(path); err != nil {
+				return nil, opErr(err)
+			} else if !ok {
+				return nil, opErr(fmt.Errorf("path %q does not exist", op.Path))
+			}
+			if op.Op == "remove" {
+				remove(path)
+			} else {
+				set(path, op.Value)
+			}
+		case "copy", "move":
+			from, err := ParseJSONPointer(op.From)
+			if err != nil {
+				return nil, opErr(err)
+			}
+			if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, opErr(fmt.Errorf("path %q is a child of from %q", op.Path, op.From))
+			}
+			v, ok, err := get(from)
+			if err != nil {
+				return nil, opErr(err)
+			}
+			if !ok {
+				return nil, opErr(fmt.Errorf("path %q does not exist", op.From))
+			}
+			if ok, err = parentExists(path); err != nil {
+				return nil, opErr(err)
+			} else if !ok {
+				return nil, opErr(fmt.Errorf("parent of path %q does not exist", op.Path))
+			}
+			if op.Op == "move" {
+				if op.From == op.Path {
+					continue
+				}
+				remove(from)
+			}
+			set(path, v)
+		case "test":
+			v, ok, err := get(path)
+			if err != nil {
+				return nil, opErr(err)
+			}
+			if !ok {
+				return nil, opErr(fmt.Errorf("path %q does not exist", op.Path))
+			}
+			if !jsonEqual(v, op.Value) {
+				return nil, opErr(fmt.Errorf("test failed"))
+			}
+		default:
+			return nil, opErr(fmt.Errorf("unknown operation"))
+		}
+	}
+	return
+}
+
+// ParseJSONPointer parses JSON Pointer (RFC 6901) to path segments
+func ParseJSONPointer(pointer string) (path []string, err error) {
+	if pointer == "" || pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q", pointer)
+	}
+	for _, seg := range strings.Split(pointer[1:], "/") {
+		seg = strings.NewReplacer("~1", "/", "~0", "~").Replace(seg)
+		if _, err := strconv.Atoi(seg); err == nil || seg == "-" {
+			return nil, fmt.Errorf("json pointer %q: array index is not supported", pointer)
+		}
+		path = append(path, seg)
+	}
+	return
+}
+
+func jsonPointer(path []string) string {
+	r := strings.NewReplacer("~", "~0", "/", "~1")
+	var s []string
+	for _, name := range path {
+		s = append(s, r.Replace(name))
+	}
+	return "/" + strings.Join(s, "/")
+}
+
+func jsonPatchSet(doc map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := doc[name].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			doc[name] = child
+		}
+		doc = child
+	}
+	doc[path[len(path)-1]] = value
+}
+
+func jsonPatchLookup(doc map[string]interface{}, path []string) (v interface{}, ok bool) {
+	for i, name := range path {
+		if v, ok = doc[name]; !ok {
+			return
+		}
+		if i < len(path)-1 {
+			if doc, ok = v.(map[string]interface{}); !ok {
+				return
+			}
+		}
+	}
+	return
+}
+
+func jsonPatchCurrentValue(context *core.Context, record interface{}, metaors []Metaor, path []string) (v interface{}, ok bool, err error) {
+	v = record
+	for i, name := range path {
+		var meta Metaor
+		for _, m := range metaors {
+			if m.GetName() == name {
+				meta = m
+				break
+			}
+		}
+		if meta == nil || meta.GetValuer() == nil {
+			return nil, false, nil
+		}
+		if v = meta.GetValuer()(v, context); v == nil {
+			// a null parent has no children
+			return nil, i == len(path)-1, nil
+		}
+		if i < len(path)-1 {
+			metaors = meta.GetContextMetas(v, context)
+		}
+	}
+	// normalize to JSON types
+	var data []byte
+	if data, err = json.Marshal(v); err != nil {
+		return
+	}
+	v = nil
+	err = json.Unmarshal(data, &v)
+	return v, err == nil, err
+}
+
+func jsonEqual(a, b interface{}) bool {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	db, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var va, vb interface{}
+	if json.Unmarshal(da, &va) != nil || json.Unmarshal(db, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+)
+
+type patchItem struct {
+	Name string
+	Note *string
+}
+
+func patchMetas() []Metaor {
+	return []Metaor{
+		&Meta{MetaName: &MetaName{Name: "Name"}, FieldName: "Name", Valuer: func(record interface{}, ctx *core.Context) interface{} {
+			return record.(*patchItem).Name
+		}},
+		&Meta{MetaName: &MetaName{Name: "Note"}, FieldName: "Note", Valuer: func(record interface{}, ctx *core.Context) interface{} {
+			if note := record.(*patchItem).Note; note != nil {
+				return *note
+			}
+			return nil
+		}},
+	}
+}
+
+func TestJSONPatchToMergePatch(t *testing.T) {
+	op := func(op, path string, value ...interface{}) *JSONPatchOperation {
+		o := &JSONPatchOperation{Op: op, Path: path}
+		if op == "move" || op == "copy" {
+			o.From, o.Path = path, value[0].(string)
+		} else if len(value) > 0 {
+			o.Value = value[0]
+		}
+		return o
+	}
+
+	cases := []struct {
+		name string
+		ops  []*JSONPatchOperation
+		want map[string]interface{}
+	}{
+		{"remove", []*JSONPatchOperation{op("remove", "/Name")}, map[string]interface{}{"Name": nil}},
+		{"remove null", []*JSONPatchOperation{op("remove", "/Note")}, map[string]interface{}{"Note": nil}},
+		{"remove missing", []*JSONPatchOperation{op("remove", "/Missing")}, nil},
+		{"remove removed", []*JSONPatchOperation{op("remove", "/Name"), op("remove", "/Name")}, nil},
+		{"remove and add", []*JSONPatchOperation{op("remove", "/Name"), op("add", "/Name", "b")}, map[string]interface{}{"Name": "b"}},
+		{"replace missing", []*JSONPatchOperation{op("replace", "/Missing", "b")}, nil},
+		{"add", []*JSONPatchOperation{op("add", "/Extra", "b")}, map[string]interface{}{"Extra": "b"}},
+		{"add without parent", []*JSONPatchOperation{op("add", "/Missing/x", "b")}, nil},
+		{"add into added", []*JSONPatchOperation{op("add", "/Extra", map[string]interface{}{}), op("add", "/Extra/x", "b")},
+			map[string]interface{}{"Extra": map[string]interface{}{"x": "b"}}},
+		{"test", []*JSONPatchOperation{op("test", "/Name", "a"), op("test", "/Note", nil)}, map[string]interface{}{}},
+		{"test failed", []*JSONPatchOperation{op("test", "/Name", "b")}, nil},
+		{"test missing", []*JSONPatchOperation{op("test", "/Missing", nil)}, nil},
+		{"test removed", []*JSONPatchOperation{op("remove", "/Name"), op("test", "/Name", nil)}, nil},
+		{"copy", []*JSONPatchOperation{op("copy", "/Name", "/Note")}, map[string]interface{}{"Note": "a"}},
+		{"copy missing", []*JSONPatchOperation{op("copy", "/Missing", "/Note")}, nil},
+		{"move", []*JSONPatchOperation{op("move", "/Name", "/Note")}, map[string]interface{}{"Name": nil, "Note": "a"}},
+		{"move to itself", []*JSONPatchOperation{op("move", "/Name", "/Name")}, map[string]interface{}{}},
+		{"move to child", []*JSONPatchOperation{op("move", "/Name", "/Name/x")}, nil},
+		{"move and test", []*JSONPatchOperation{op("move", "/Name", "/Note"), op("test", "/Name", nil)}, nil},
+	}
+
+	for _, c := range cases {
+		doc, err := JSONPatchToMergePatch(core.NewContext(), c.ops, &patchItem{Name: "a"}, patchMetas())
+		if c.want == nil {
+			if _, ok := err.(*JSONPatchError); !ok {
+				t.Errorf("%s: expected patch error, but got %v", c.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		} else if !reflect.DeepEqual(doc, c.want) {
+			t.Errorf("%s: expected %v, but got %v", c.name, c.want, doc)
+		}
+	}
+}
+
+func TestJSONMergePatchNull(t *testing.T) {
+	metaValues, err := ConvertJSONMergePatchToMetaValues(core.NewContext(), strings.NewReader(`{"Name": "b", "Note": null}`), patchMetas())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, null := range map[string]bool{"Name": false, "Note": true} {
+		if metaValue := metaValues.ByName[name]; metaValue == nil || metaValue.Null != null {
+			t.Errorf("%s: expected null %v, but got %v", name, null, metaValue)
+		}
+	}
+}
+
+func TestSetterNull(t *testing.T) {
+	note := "n"
+	cases := map[string]func(meta *Meta) func(record interface{}, metaValue *MetaValue, context *core.Context) error{
+		"GenericSetter": func(meta *Meta) func(record interface{}, metaValue *MetaValue, context *core.Context) error {
+			return GenericSetter(meta, meta.FieldName, func(field reflect.Value, metaValue *MetaValue, context *core.Context, record interface{}) error {
+				t.Errorf("%s: unexpected set", meta.Name)
+				return nil
+			})
+		},
+		"SingleFieldSetter": func(meta *Meta) func(record interface{}, metaValue *MetaValue, context *core.Context) error {
+			return SingleFieldSetter(meta.FieldName, func(ptr bool, field reflect.Value, metaValue *MetaValue, context *core.Context, record interface{}) error {
+				t.Errorf("%s: unexpected set", meta.Name)
+				return nil
+			})
+		},
+	}
+
+	for name, setter := range cases {
+		record := &patchItem{Name: "a", Note: &note}
+		for _, metaor := range patchMetas() {
+			meta := metaor.(*Meta)
+			if err := setter(meta)(record, &MetaValue{Name: meta.Name, Meta: meta, Null: true}, core.NewContext()); err != nil {
+				t.Errorf("%s: %s: unexpected error: %v", name, meta.Name, err)
+			}
+		}
+		if record.Name != "" || record.Note != nil {
+			t.Errorf("%s: expected cleared record, but got %+v", name, record)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// ErrNotSearchable is returned by CRUD.Search if resource does not have searchable fields
+var ErrNotSearchable = errors.New("resource: not searchable")
+
+// SearchBackends are the search backends by dialect name. Unregistered dialects uses LikeSearchBackend.
+var SearchBackends = map[string]SearchBackend{
+	"postgres": &PostgresSearchBackend{Language: "simple"},
+	"sqlite3":  &SQLiteSearchBackend{},
+	"mysql":    &MySQLSearchBackend{},
+}
+
+// SearchHit is the matched record with ranking and highlighting data
+type SearchHit struct {
+	Record    interface{}
+	Rank      float64
+	Highlight string
+}
+
+// SearchMatch is a record ID matched by backend
+type SearchMatch struct {
+	ID        interface{}
+	Rank      float64
+	Highlight string
+}
+
+// SearchQuery is the search request
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Offset int
+}
+
+// Searchable is the search config of resource
+type Searchable struct {
+	Fields []*aorm.StructField
+}
+
+// Content returns the searchable text of record
+func (this *Searchable) Content(record interface{}) string {
+	var (
+		value = reflect.Indirect(reflect.ValueOf(record))
+		parts []string
+	)
+	for _, field := range this.Fields {
+		v := reflect.Indirect(value.FieldByIndex(field.StructIndex))
+		if !v.IsValid() {
+			continue
+		}
+		if s := strings.TrimSpace(fmt.Sprint(v.Interface())); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchBackend is the full text search backend
+type SearchBackend interface {
+	// Migrate creates the search indexes
+	Migrate(res *Resource, db *aorm.DB) error
+	// Match returns the record IDs matched by query ordered by rank
+	Match(res *Resource, db *aorm.DB, query *SearchQuery) ([]SearchMatch, error)
+}
+
+// SearchIndexer is the search backend with side index maintained by DB events
+type SearchIndexer interface {
+	Index(res *Resource, db *aorm.DB, record interface{}) error
+	Unindex(res *Resource, db *aorm.DB, record interface{}) error
+}
+
+// SearchBackendOf returns the search backend of db dialect
+func SearchBackendOf(db *aorm.DB) SearchBackend {
+	if backend, ok := SearchBackends[db.Dialect().GetName()]; ok {
+		return backend
+	}
+	return &LikeSearchBackend{}
+}
+
+// SetSearchable sets the searchable metas of resource. Metas are resolved by field name and must be
+// normal fields. The resource must have a single primary field.
+func (res *Resource) SetSearchable(metaNames ...string) (err error) {
+	if len(res.PrimaryFields) != 1 {
+		return fmt.Errorf("resource %q: search requires a single primary field", res.UID)
+	}
+	searchable := &Searchable{}
+	for _, name := range metaNames {
+		field, ok := res.ModelStruct.FieldsByName[name]
+		if !ok || !field.IsNormal {
+			return fmt.Errorf("resource %q: %q is not a searchable field", res.UID, name)
+		}
+		searchable.Fields = append(searchable.Fields, field)
+	}
+	res.Searchable = searchable
+
+	if err = res.OnDBActionE(func(e *DBEvent) error {
+		return res.searchIndex(e, false)
+	}, E_DB_ACTION_CREATE.After(), E_DB_ACTION_UPDATE.After(), E_DB_ACTION_CREATE_MANY.After(),
+		E_DB_ACTION_UPDATE_MANY.After(), E_DB_ACTION_RESTORE.After()); err != nil {
+		return
+	}
+	return res.OnDBActionE(func(e *DBEvent) error {
+		return res.searchIndex(e, true)
+	}, E_DB_ACTION_DELETE.After(), E_DB_ACTION_DELETE_MANY.After(), E_DB_ACTION_PURGE.After())
+}
+
+// IsSearchable returns if resource has searchable fields
+func (res *Resource) IsSearchable() bool {
+	return res.Searchable != nil && len(res.Searchable.Fields) > 0
+}
+
+// MigrateSearch creates the search indexes of resource
+func (res *Resource) MigrateSearch(db *aorm.DB) error {
+	if !res.IsSearchable() {
+		return ErrNotSearchable
+	}
+	return SearchBackendOf(db).Migrate(res, db)
+}
+
+func (res *Resource) searchIndex(e *DBEvent, remove bool) (err error) {
+	indexer, ok := SearchBackendOf(e.DB()).(SearchIndexer)
+	if !ok {
+		return
+	}
+	records := e.Records()
+	if records == nil {
+		records = []interface{}{e.Result()}
+	}
+	db := e.DB().New()
+	for _, record := range records {
+		if remove {
+			err = indexer.Unindex(res, db, record)
+		} else {
+			err = indexer.Index(res, db, record)
+		}
+		if err != nil {
+			return errwrap.Wrap(err, "Resource %q: search index", res.UID)
+		}
+	}
+	return
+}
+
+// Search finds records matched by query text into result and returns the hits ordered by rank.
+// Records are loaded by FindMany of a sub CRUD, so all of find many DB events are applied. The conditions of CRUD
+// DB are applied to match query, and query Limit and Offset (if set) replace the DB ones. The backends query aorm,
+// so Search returns ErrUnsupportedRepository if resource has other repository.
+func (this *CRUD) Search(query *SearchQuery, result interface{}) (hits []SearchHit, err error) {
+	res := this.res.GetResource()
+	if !res.IsSearchable() {
+		return nil, ErrNotSearchable
+	}
+	if !isAormRepository(this.repository()) {
+		return nil, errwrap.Wrap(ErrUnsupportedRepository, "Search of resource %q", res.UID)
+	}
+
+	var (
+		DB      = this.DB()
+		backend = SearchBackendOf(DB)
+		matches []SearchMatch
+	)
+
+	// match DB isolates tenant and deleted records, so limit and offset are applied to visible records only
+	matchDB := DB
+	if matchDB, err = res.TenantDB(this.context, matchDB); err != nil {
+		return
+	}
+	if res.SoftDelete != nil {
+		matchDB = res.SoftDelete.Scope(res, matchDB, SoftDeleteModeOf(DB))
+	}
+
+	if matches, err = backend.Match(res, matchDB, query); err != nil || len(matches) == 0 {
+		return
+	}
+
+	var (
+		scope = DB.NewScope(res.Value)
+		ids   = make([]interface{}, len(matches))
+	)
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+
+	// matches are paginated, so the records are loaded without limit and offset
+	DB = DB.Where(fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(res.PrimaryFields[0].DBName)), ids).
+		Limit(-1).Offset(-1)
+	if err = this.SetContext(this.context.Clone().SetDB(DB)).FindMany(result); err != nil {
+		return
+	}
+
+	var (
+		slice   = reflect.Indirect(reflect.ValueOf(result))
+		records = map[string]interface{}{}
+		terms   = searchTerms(query.Text)
+	)
+	for i := 0; i < slice.Len(); i++ {
+		record := slice.Index(i).Interface()
+		pk := reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(res.PrimaryFields[0].StructIndex)
+		records[fmt.Sprint(pk.Interface())] = record
+	}
+
+	for _, m := range matches {
+		record, ok := records[fmt.Sprint(m.ID)]
+		if !ok {
+			continue
+		}
+		hit := SearchHit{Record: record, Rank: m.Rank, Highlight: searchHighlightEscape(m.Highlight)}
+		if hit.Highlight == "" {
+			content := res.Searchable.Content(record)
+			hit.Highlight = SearchHighlight(content, terms)
+			if hit.Rank == 0 {
+				hit.Rank = float64(searchCount(content, terms))
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Rank > hits[j].Rank
+	})
+
+	for i, hit := range hits {
+		slice.Index(i).Set(reflect.ValueOf(hit.Record))
+	}
+	slice.Set(slice.Slice(0, len(hits)))
+	return
+}
+
+// SearchHighlightStart and SearchHighlightStop are the markers of matched terms into backend highlights. The
+// highlights are html escaped and the markers are replaced by `<b>` tags.
+const (
+	SearchHighlightStart = "{{{b}}}"
+	SearchHighlightStop  = "{{{/b}}}"
+)
+
+func searchHighlightEscape(highlight string) string {
+	if highlight == "" {
+		return ""
+	}
+	return strings.NewReplacer(SearchHighlightStart, "<b>", SearchHighlightStop, "</b>").Replace(html.EscapeString(highlight))
+}
+
+func searchTerms(text string) []string {
+	return strings.Fields(text)
+}
+
+func searchCount(content string, terms []string) (count int) {
+	content = strings.ToLower(content)
+	for _, term := range terms {
+		count += strings.Count(content, strings.ToLower(term))
+	}
+	return
+}
+
+// SearchHighlight returns the html escaped content with terms wrapped into `<b>` tags
+func SearchHighlight(content string, terms []string) string {
+	var (
+		lower = strings.ToLower(content)
+		b     strings.Builder
+		pos   int
+	)
+	for pos < len(content) {
+		next, size := -1, 0
+		for _, term := range terms {
+			if i := strings.Index(lower[pos:], strings.ToLower(term)); i >= 0 && (next < 0 || i < next) {
+				next, size = i, len(term)
+			}
+		}
+		if next < 0 || size == 0 {
+			break
+		}
+		b.WriteString(html.EscapeString(content[pos : pos+next]))
+		b.WriteString("<b>" + html.EscapeString(content[pos+next:pos+next+size]) + "</b>")
+		pos += next + size
+	}
+	b.WriteString(html.EscapeString(content[pos:]))
+	return b.String()
+}
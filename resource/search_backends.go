@@ -0,0 +1,241 @@
+package resource
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// ErrSearchLanguage is returned by PostgresSearchBackend if Language is not a valid text search config name
+var ErrSearchLanguage = errors.New("resource: invalid search language")
+
+var searchLanguageRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PostgresSearchBackend searches using `tsvector` of searchable fields. Language is the text search config name,
+// such as "simple" or "english".
+type PostgresSearchBackend struct {
+	Language string
+}
+
+// language returns the quoted Language. It's inlined, instead of bound, to match the index expression.
+func (this *PostgresSearchBackend) language() (string, error) {
+	if !searchLanguageRegex.MatchString(this.Language) {
+		return "", errwrap.Wrap(ErrSearchLanguage, "%q", this.Language)
+	}
+	return "'" + this.Language + "'", nil
+}
+
+func (this *PostgresSearchBackend) document(res *Resource, scope *aorm.Scope) string {
+	var parts []string
+	for _, field := range res.Searchable.Fields {
+		parts = append(parts, fmt.Sprintf("coalesce(%v.%v::text, '')", scope.FromName(), scope.Quote(field.DBName)))
+	}
+	return strings.Join(parts, " || ' ' || ")
+}
+
+func (this *PostgresSearchBackend) Migrate(res *Resource, db *aorm.DB) error {
+	lang, err := this.language()
+	if err != nil {
+		return err
+	}
+	scope := db.NewScope(res.Value)
+	return db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %v ON %v USING GIN (to_tsvector(%v, %v))",
+		scope.Quote(scope.TableName()+"_search_idx"), scope.QuotedTableName(), lang, this.document(res, scope))).Error
+}
+
+func (this *PostgresSearchBackend) Match(res *Resource, db *aorm.DB, query *SearchQuery) ([]SearchMatch, error) {
+	lang, err := this.language()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		scope   = db.NewScope(res.Value)
+		doc     = this.document(res, scope)
+		tsq     = fmt.Sprintf("plainto_tsquery(%v, ?)", lang)
+		options = fmt.Sprintf("StartSel=%v, StopSel=%v", SearchHighlightStart, SearchHighlightStop)
+	)
+	return searchMatches(res, searchPaginate(db.Model(res.Value).
+		Select(fmt.Sprintf("%v.%v, ts_rank(to_tsvector(%v, %v), %v) AS search_rank, ts_headline(%v, %v, %v, ?)",
+			scope.FromName(), scope.Quote(res.PrimaryFields[0].DBName), lang, doc, tsq, lang, doc, tsq),
+			query.Text, query.Text, options).
+		Where(fmt.Sprintf("to_tsvector(%v, %v) @@ %v", lang, doc, tsq), query.Text).
+		Order("search_rank DESC"), query))
+}
+
+// SQLiteSearchBackend searches using a FTS5 side index table named `<table>_fts`
+type SQLiteSearchBackend struct{}
+
+func (this *SQLiteSearchBackend) table(scope *aorm.Scope) string {
+	return scope.Quote(scope.TableName() + "_fts")
+}
+
+// Migrate creates the FTS table and indexes the records missing from it, such as the records created before the
+// search was enabled.
+func (this *SQLiteSearchBackend) Migrate(res *Resource, db *aorm.DB) (err error) {
+	var (
+		scope = db.NewScope(res.Value)
+		fts   = this.table(scope)
+		pk    = fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(res.PrimaryFields[0].DBName))
+		parts []string
+	)
+	if err = db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %v USING fts5(record_id UNINDEXED, content)",
+		fts)).Error; err != nil {
+		return
+	}
+	for _, field := range res.Searchable.Fields {
+		parts = append(parts, fmt.Sprintf("coalesce(CAST(%v.%v AS TEXT), '')", scope.QuotedTableName(), scope.Quote(field.DBName)))
+	}
+	where := fmt.Sprintf("%v NOT IN (SELECT record_id FROM %v)", pk, fts)
+	if res.SoftDelete != nil {
+		where += fmt.Sprintf(" AND %v.%v IS NULL", scope.QuotedTableName(), scope.Quote(res.SoftDelete.Field.DBName))
+	}
+	return db.Exec(fmt.Sprintf("INSERT INTO %v (record_id, content) SELECT %v, trim(%v) FROM %v WHERE %v",
+		fts, pk, strings.Join(parts, " || ' ' || "), scope.QuotedTableName(), where)).Error
+}
+
+func (this *SQLiteSearchBackend) Match(res *Resource, db *aorm.DB, query *SearchQuery) ([]SearchMatch, error) {
+	var (
+		scope = db.NewScope(res.Value)
+		fts   = this.table(scope)
+		pk    = fmt.Sprintf("%v.%v", scope.FromName(), scope.Quote(res.PrimaryFields[0].DBName))
+		terms []string
+	)
+	// quote terms to disable FTS5 query syntax
+	for _, term := range searchTerms(query.Text) {
+		terms = append(terms, `"`+strings.Replace(term, `"`, `""`, -1)+`"`)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	return searchMatches(res, searchPaginate(db.Model(res.Value).
+		Select(fmt.Sprintf("%v, -bm25(%v) AS search_rank, snippet(%v, 1, ?, ?, '...', 16)", pk, fts, fts),
+			SearchHighlightStart, SearchHighlightStop).
+		Joins(fmt.Sprintf("JOIN %v ON %v.record_id = %v", fts, fts, pk)).
+		Where(fts+" MATCH ?", strings.Join(terms, " ")).
+		Order("search_rank DESC"), query))
+}
+
+func (this *SQLiteSearchBackend) Index(res *Resource, db *aorm.DB, record interface{}) (err error) {
+	if err = this.Unindex(res, db, record); err != nil {
+		return
+	}
+	return db.Exec(fmt.Sprintf("INSERT INTO %v (record_id, content) VALUES (?, ?)", this.table(db.NewScope(res.Value))),
+		searchRecordID(res, record), res.Searchable.Content(record)).Error
+}
+
+func (this *SQLiteSearchBackend) Unindex(res *Resource, db *aorm.DB, record interface{}) error {
+	return db.Exec(fmt.Sprintf("DELETE FROM %v WHERE record_id = ?", this.table(db.NewScope(res.Value))),
+		searchRecordID(res, record)).Error
+}
+
+// MySQLSearchBackend searches using FULLTEXT index of searchable fields
+type MySQLSearchBackend struct{}
+
+func (this *MySQLSearchBackend) match(res *Resource, scope *aorm.Scope) string {
+	var columns []string
+	for _, field := range res.Searchable.Fields {
+		columns = append(columns, fmt.Sprintf("%v.%v", scope.FromName(), scope.Quote(field.DBName)))
+	}
+	return "MATCH (" + strings.Join(columns, ", ") + ") AGAINST (? IN NATURAL LANGUAGE MODE)"
+}
+
+func (this *MySQLSearchBackend) Migrate(res *Resource, db *aorm.DB) error {
+	var (
+		scope   = db.NewScope(res.Value)
+		columns []string
+	)
+	for _, field := range res.Searchable.Fields {
+		columns = append(columns, scope.Quote(field.DBName))
+	}
+	err := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %v ON %v (%v)", scope.Quote(scope.TableName()+"_search_idx"),
+		scope.QuotedTableName(), strings.Join(columns, ", "))).Error
+	if err != nil && strings.Contains(err.Error(), "Duplicate key name") {
+		return nil
+	}
+	return err
+}
+
+func (this *MySQLSearchBackend) Match(res *Resource, db *aorm.DB, query *SearchQuery) ([]SearchMatch, error) {
+	var (
+		scope = db.NewScope(res.Value)
+		match = this.match(res, scope)
+	)
+	return searchMatches(res, searchPaginate(db.Model(res.Value).
+		Select(fmt.Sprintf("%v.%v, %v AS search_rank, ''", scope.FromName(), scope.Quote(res.PrimaryFields[0].DBName), match), query.Text).
+		Where(match, query.Text).
+		Order("search_rank DESC"), query))
+}
+
+// LikeSearchBackend searches using LIKE conditions. Every term must be found in any searchable field.
+// Rank and highlight are computed after loading records.
+type LikeSearchBackend struct{}
+
+func (LikeSearchBackend) Migrate(res *Resource, db *aorm.DB) error {
+	return nil
+}
+
+func (LikeSearchBackend) Match(res *Resource, db *aorm.DB, query *SearchQuery) ([]SearchMatch, error) {
+	var (
+		scope = db.NewScope(res.Value)
+		terms = searchTerms(query.Text)
+	)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	db = db.Model(res.Value).Select(fmt.Sprintf("%v.%v, 0, ''", scope.FromName(), scope.Quote(res.PrimaryFields[0].DBName)))
+	for _, term := range terms {
+		var (
+			ors  []string
+			args []interface{}
+		)
+		for _, field := range res.Searchable.Fields {
+			ors = append(ors, fmt.Sprintf(`LOWER(%v.%v) LIKE ? ESCAPE '!'`, scope.FromName(), scope.Quote(field.DBName)))
+			args = append(args, "%"+likeEscaper.Replace(strings.ToLower(term))+"%")
+		}
+		db = db.Where(strings.Join(ors, " OR "), args...)
+	}
+	return searchMatches(res, searchPaginate(db, query))
+}
+
+func searchPaginate(db *aorm.DB, query *SearchQuery) *aorm.DB {
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	return db
+}
+
+// searchMatches scans rows of (primary key, rank, highlight)
+func searchMatches(res *Resource, db *aorm.DB) (matches []SearchMatch, err error) {
+	var rows *sql.Rows
+	if rows, err = db.Rows(); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id        = reflect.New(res.PrimaryFields[0].Struct.Type)
+			m         SearchMatch
+			highlight sql.NullString
+		)
+		if err = rows.Scan(id.Interface(), &m.Rank, &highlight); err != nil {
+			return
+		}
+		m.ID, m.Highlight = id.Elem().Interface(), highlight.String
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+func searchRecordID(res *Resource, record interface{}) interface{} {
+	return reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(res.PrimaryFields[0].StructIndex).Interface()
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestSearchHighlightEscape(t *testing.T) {
+	cases := []struct {
+		highlight string
+		want      string
+	}{
+		{"", ""},
+		{"a " + SearchHighlightStart + "b" + SearchHighlightStop + " c", "a <b>b</b> c"},
+		{"<script>" + SearchHighlightStart + "x" + SearchHighlightStop, "&lt;script&gt;<b>x</b>"},
+		{"<b>x</b>", "&lt;b&gt;x&lt;/b&gt;"},
+	}
+	for i, c := range cases {
+		if got := searchHighlightEscape(c.highlight); got != c.want {
+			t.Errorf("#%d: expected %q, but got %q", i, c.want, got)
+		}
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	if got, want := SearchHighlight("<i>Go</i> go", []string{"go"}), "&lt;i&gt;<b>Go</b>&lt;/i&gt; <b>go</b>"; got != want {
+		t.Errorf("expected %q, but got %q", want, got)
+	}
+}
+
+func TestPostgresSearchBackendLanguage(t *testing.T) {
+	cases := []struct {
+		language string
+		valid    bool
+	}{
+		{"simple", true},
+		{"english", true},
+		{"pg_catalog", true},
+		{"", false},
+		{"simple', now()) --", false},
+		{"a b", false},
+	}
+	for i, c := range cases {
+		_, err := (&PostgresSearchBackend{Language: c.language}).language()
+		if (err == nil) != c.valid {
+			t.Errorf("#%d: %q: unexpected error %v", i, c.language, err)
+		}
+	}
+}
+
+type searchItem struct {
+	ID   int64 `aorm:"primary_key"`
+	Name string
+	Note string
+}
+
+func TestSQLiteSearchMigrate(t *testing.T) {
+	db := testDB(t, &searchItem{})
+	if db.Dialect().GetName() != "sqlite3" {
+		t.Skip("not a sqlite3 database")
+	}
+	db.Exec("DROP TABLE IF EXISTS search_items_fts")
+
+	// records created before the search was enabled
+	for _, item := range []*searchItem{{Name: "red apple"}, {Name: "green", Note: "apple pie"}, {Name: "banana"}} {
+		if err := db.Create(item).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res := New(&searchItem{}, "", "", nil)
+	if err := res.SetSearchable("Name", "Note"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := res.MigrateSearch(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int
+	if err := db.Table("search_items_fts").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 indexed records, but got %d", count)
+	}
+
+	var items []searchItem
+	hits, err := res.Crud(testContext(db)).Search(&SearchQuery{Text: "apple"}, &items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 {
+		t.Errorf("expected 2 hits, but got %d", len(hits))
+	}
+}
@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ecletus/roles"
+	"github.com/go-aorm/aorm"
+)
+
+const DefaultSoftDeleteFieldName = "DeletedAt"
+
+// SOFT_DELETE_MODE_KEY is the DB setting key of the SoftDeleteMode used by queries of soft delete resources
+var SOFT_DELETE_MODE_KEY = pkg + ".soft_delete_mode"
+
+// ErrSoftDeleteDisabled is returned when restore a record of resource without soft delete
+var ErrSoftDeleteDisabled = errors.New("resource: soft delete is disabled")
+
+type SoftDeleteMode uint8
+
+const (
+	// SoftDeleteExclude excludes deleted records (default)
+	SoftDeleteExclude SoftDeleteMode = iota
+	// SoftDeleteInclude includes deleted records
+	SoftDeleteInclude
+	// SoftDeleteOnly selects only deleted records
+	SoftDeleteOnly
+)
+
+// SoftDeleteModeOf returns the soft delete mode of db
+func SoftDeleteModeOf(db *aorm.DB) SoftDeleteMode {
+	if v, ok := db.Get(SOFT_DELETE_MODE_KEY); ok {
+		return v.(SoftDeleteMode)
+	}
+	return SoftDeleteExclude
+}
+
+// SoftDelete is the soft delete config of resource. The field type is `*time.Time`.
+type SoftDelete struct {
+	FieldName string
+	Field     *aorm.StructField
+}
+
+// Scope returns db with deleted records condition of mode
+func (this *SoftDelete) Scope(res Resourcer, db *aorm.DB, mode SoftDeleteMode) *aorm.DB {
+	var (
+		scope  = db.NewScope(res.GetValue())
+		column = fmt.Sprintf("%v.%v", scope.FromName(), scope.Quote(this.Field.DBName))
+	)
+	switch mode {
+	case SoftDeleteExclude:
+		return db.Where(column + " IS NULL")
+	case SoftDeleteOnly:
+		return db.Where(column + " IS NOT NULL")
+	}
+	return db
+}
+
+// Mark sets deletedAt to record and updates the column. If deletedAt is nil, the record is restored.
+func (this *SoftDelete) Mark(res Resourcer, db *aorm.DB, record interface{}, deletedAt *time.Time) (err error) {
+	var value interface{}
+	if deletedAt != nil {
+		value = *deletedAt
+	}
+	if err = db.Model(record).UpdateColumn(this.Field.DBName, value).Error; err == nil {
+		reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(this.Field.StructIndex).Set(reflect.ValueOf(deletedAt))
+	}
+	return
+}
+
+// SetSoftDelete enables soft delete of resource records using the `*time.Time` field fieldName (default is
+// DefaultSoftDeleteFieldName). Deleted records are excluded from queries unless the CRUD DB asks for them (see
+// CRUD.WithDeleted and CRUD.OnlyDeleted).
+func (res *Resource) SetSoftDelete(fieldName ...string) error {
+	if res.SoftDelete != nil {
+		return nil
+	}
+	var name = DefaultSoftDeleteFieldName
+	for _, name = range fieldName {
+	}
+	field, ok := res.ModelStruct.FieldsByName[name]
+	if !ok {
+		return fmt.Errorf("%v is not a valid field for resource %v", name, res.Name)
+	}
+	if field.Struct.Type != reflect.TypeOf(&time.Time{}) {
+		return fmt.Errorf("soft delete field %v of resource %v isn't a *time.Time", name, res.Name)
+	}
+	res.SoftDelete = &SoftDelete{FieldName: name, Field: field}
+
+	return res.OnDBActionE(func(e *DBEvent) (err error) {
+		e.DB(res.SoftDelete.Scope(res, e.DB(), SoftDeleteModeOf(e.DB())))
+		return
+	}, E_DB_ACTION_FIND_ONE.Before(), E_DB_ACTION_FIND_MANY.Before(), E_DB_ACTION_COUNT.Before())
+}
+
+// WithDeleted returns a sub CRUD whose queries include soft deleted records
+func (this *CRUD) WithDeleted() *CRUD {
+	return this.softDeleteMode(SoftDeleteInclude)
+}
+
+// OnlyDeleted returns a sub CRUD whose queries select only soft deleted records
+func (this *CRUD) OnlyDeleted() *CRUD {
+	return this.softDeleteMode(SoftDeleteOnly)
+}
+
+func (this *CRUD) softDeleteMode(mode SoftDeleteMode) *CRUD {
+	return this.SetContext(this.context.Clone().SetDB(this.DB().Set(SOFT_DELETE_MODE_KEY, mode)))
+}
+
+// Restore restores the soft deleted record
+func (this *CRUD) Restore(record interface{}) error {
+	if this.HasPermission(roles.Update) {
+		return this.CallRestore(record)
+	}
+	return roles.ErrPermissionDenied
+}
+
+func (this *CRUD) CallRestore(record interface{}) error {
+	sd := this.res.GetResource().SoftDelete
+	if sd == nil {
+		return ErrSoftDeleteDisabled
+	}
+	return this.callDelete(record, E_DB_ACTION_RESTORE, this.recordKey(record), SoftDeleteOnly, func(ctx *RepositoryContext) error {
+		return sd.Mark(this.res, ctx.DB(), record, nil)
+	})
+}
+
+// Purge deletes the record permanently, including soft deleted records
+func (this *CRUD) Purge(record interface{}) error {
+	if this.HasPermission(roles.Delete) {
+		return this.CallPurge(record)
+	}
+	return roles.ErrPermissionDenied
+}
+
+func (this *CRUD) CallPurge(record interface{}) error {
+	return this.callDelete(record, E_DB_ACTION_PURGE, this.recordKey(record), SoftDeleteInclude, func(ctx *RepositoryContext) error {
+		return ctx.DB().Unscoped().Delete(record).Error
+	})
+}
+
+func (this *CRUD) recordKey(record interface{}) (id aorm.ID) {
+	if id = this.res.GetKey(record); id == nil || id.IsZero() {
+		id = this.context.ResourceID
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+)
+
+type softItem struct {
+	ID        int64 `aorm:"primary_key"`
+	Name      string
+	DeletedAt *time.Time
+}
+
+func TestSoftDelete(t *testing.T) {
+	var (
+		db  = testDB(t, &softItem{})
+		res = New(&softItem{}, "", "", nil)
+	)
+	if err := res.SetSoftDelete(); err != nil {
+		t.Fatal(err)
+	}
+	crud := res.Crud(testContext(db))
+	a, b := &softItem{Name: "a"}, &softItem{Name: "b"}
+	for _, r := range []*softItem{a, b} {
+		if err := crud.CallCreate(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := testContext(db)
+	ctx.ResourceID = res.GetKey(a)
+	if err := res.Crud(ctx).CallDelete(&softItem{}); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(crud *CRUD) int {
+		var items []softItem
+		if err := crud.FindMany(&items); err != nil {
+			t.Fatal(err)
+		}
+		return len(items)
+	}
+	cases := []struct {
+		name string
+		crud *CRUD
+		want int
+	}{
+		{"default", crud, 1},
+		{"with deleted", crud.WithDeleted(), 2},
+		{"only deleted", crud.OnlyDeleted(), 1},
+		{"default after sub", crud, 1},
+	}
+	for _, c := range cases {
+		if got := count(c.crud); got != c.want {
+			t.Errorf("%s: expected %d records, but got %d", c.name, c.want, got)
+		}
+	}
+	if err := crud.FindOne(&softItem{}, res.GetKey(a)); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("find deleted: expected not found error, but got %v", err)
+	}
+	var deleted softItem
+	if err := crud.OnlyDeleted().FindOne(&deleted, res.GetKey(a)); err != nil || deleted.DeletedAt == nil {
+		t.Errorf("find only deleted: expected deleted record, but got %+v, %v", deleted, err)
+	}
+
+	if err := crud.CallRestore(&softItem{ID: b.ID}); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("restore not deleted: expected not found error, but got %v", err)
+	}
+	restored := &softItem{ID: a.ID}
+	if err := crud.CallRestore(restored); err != nil {
+		t.Fatalf("restore: unexpected error: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("restore: expected blank deleted at, but got %v", restored.DeletedAt)
+	}
+	if got := count(crud); got != 2 {
+		t.Errorf("restore: expected 2 records, but got %d", got)
+	}
+
+	if err := crud.CallPurge(&softItem{ID: b.ID}); err != nil {
+		t.Fatalf("purge: unexpected error: %v", err)
+	}
+	if got := count(crud.WithDeleted()); got != 1 {
+		t.Errorf("purge: expected 1 record, but got %d", got)
+	}
+}
+
+func TestSoftDeleteDisabled(t *testing.T) {
+	res := New(&softItem{}, "", "", nil)
+	if err := res.Crud(core.NewContext()).CallRestore(&softItem{ID: 1}); err != ErrSoftDeleteDisabled {
+		t.Errorf("expected %v, but got %v", ErrSoftDeleteDisabled, err)
+	}
+}
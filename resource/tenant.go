@@ -0,0 +1,217 @@
+package resource
+
+import (
+	"database/sql"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+const DefaultTenantFieldName = "SiteID"
+
+// ErrTenantNotInContext is returned when a query on tenant scoped resource runs without site in context
+var ErrTenantNotInContext = errors.New("resource: tenant scoped query without site in context")
+
+// ErrTenantFieldType is returned when the tenant ID can't be converted to the tenant field type
+var ErrTenantFieldType = errors.New("resource: unsupported tenant field type")
+
+// TenantScope is the row-level tenant isolation config of resource
+type TenantScope struct {
+	FieldName string
+	Field     *aorm.StructField
+}
+
+// Where returns db with tenant condition
+func (this *TenantScope) Where(res Resourcer, db *aorm.DB, tenantID string) *aorm.DB {
+	scope := db.NewScope(res.GetValue())
+	return db.Where(fmt.Sprintf("%v.%v = ?", scope.FromName(), scope.Quote(this.Field.DBName)), tenantID)
+}
+
+// Stamp sets the tenant ID to record, converted to the tenant field type. The field type (or its pointer) must be
+// a string, an integer, an encoding.TextUnmarshaler or a sql.Scanner.
+func (this *TenantScope) Stamp(record interface{}, tenantID string) (err error) {
+	var (
+		field = reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(this.Field.StructIndex)
+		typ   = field.Type()
+		isPtr = typ.Kind() == reflect.Ptr
+	)
+	if isPtr {
+		typ = typ.Elem()
+	}
+	value := reflect.New(typ)
+	if err = parseTenantID(value, tenantID); err != nil {
+		return errwrap.Wrap(err, "stamp tenant field %q", this.FieldName)
+	}
+	if isPtr {
+		field.Set(value)
+	} else {
+		field.Set(value.Elem())
+	}
+	return nil
+}
+
+// Check stamps record if its tenant field is zero, otherwise returns aorm.ErrRecordNotFound if record belongs to
+// other tenant: from the tenantID point of view, the record does not exist.
+func (this *TenantScope) Check(record interface{}, tenantID string) (err error) {
+	field := reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(this.Field.StructIndex)
+	if field.IsZero() {
+		return this.Stamp(record, tenantID)
+	}
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ, field = typ.Elem(), field.Elem()
+	}
+	value := reflect.New(typ)
+	if err = parseTenantID(value, tenantID); err != nil {
+		return errwrap.Wrap(err, "check tenant field %q", this.FieldName)
+	}
+	if !reflect.DeepEqual(value.Elem().Interface(), field.Interface()) {
+		return aorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// parseTenantID sets tenantID to value pointer
+func parseTenantID(ptr reflect.Value, tenantID string) (err error) {
+	switch t := ptr.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return t.UnmarshalText([]byte(tenantID))
+	case sql.Scanner:
+		return t.Scan(tenantID)
+	}
+	value := ptr.Elem()
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(tenantID)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(tenantID, 10, value.Type().Bits()); err == nil {
+			value.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(tenantID, 10, value.Type().Bits()); err == nil {
+			value.SetUint(u)
+		}
+	default:
+		err = errwrap.Wrap(ErrTenantFieldType, "%s", value.Type())
+	}
+	return
+}
+
+// isTenantFieldType returns if tenant ID can be converted to typ
+func isTenantFieldType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	ptr := reflect.PtrTo(typ)
+	if ptr.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+		ptr.Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem()) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// SetTenantScoped enable row-level tenant isolation for resource records. The records are stamped and filtered
+// by fieldName (default is DefaultTenantFieldName) when the context site uses row isolation.
+func (res *Resource) SetTenantScoped(fieldName ...string) (err error) {
+	if res.TenantScope != nil {
+		return nil
+	}
+	var name = DefaultTenantFieldName
+	for _, name = range fieldName {
+	}
+	field, ok := res.ModelStruct.FieldsByName[name]
+	if !ok {
+		return fmt.Errorf("%v is not a valid tenant field for resource %v", name, res.Name)
+	}
+	if !isTenantFieldType(field.Struct.Type) {
+		return errwrap.Wrap(ErrTenantFieldType, "field %v of resource %v", name, res.Name)
+	}
+	res.TenantScope = &TenantScope{FieldName: name, Field: field}
+
+	if err = res.OnDBActionE(func(e *DBEvent) (err error) {
+		var tenantID string
+		if tenantID, err = res.tenantID(e.Context); err != nil || tenantID == "" {
+			return
+		}
+		e.DB(res.TenantScope.Where(res, e.DB(), tenantID))
+		return
+	}, E_DB_ACTION_FIND_ONE.Before(), E_DB_ACTION_FIND_MANY.Before(), E_DB_ACTION_COUNT.Before(),
+		E_DB_ACTION_DELETE_MANY.Before()); err != nil {
+		return
+	}
+
+	if err = res.OnDBActionE(func(e *DBEvent) (err error) {
+		var tenantID string
+		if tenantID, err = res.tenantID(e.Context); err != nil || tenantID == "" {
+			return
+		}
+		for _, record := range tenantRecords(e) {
+			if err = res.TenantScope.Stamp(record, tenantID); err != nil {
+				return
+			}
+		}
+		return
+	}, E_DB_ACTION_CREATE.Before(), E_DB_ACTION_CREATE_MANY.Before()); err != nil {
+		return
+	}
+
+	// updates are not stamped: the records of other tenants are not found, so they can't be moved to this tenant
+	return res.OnDBActionE(func(e *DBEvent) (err error) {
+		var tenantID string
+		if tenantID, err = res.tenantID(e.Context); err != nil || tenantID == "" {
+			return
+		}
+		for _, record := range tenantRecords(e) {
+			if err = res.TenantScope.Check(record, tenantID); err != nil {
+				return
+			}
+		}
+		e.DB(res.TenantScope.Where(res, e.DB(), tenantID))
+		return
+	}, E_DB_ACTION_UPDATE.Before(), E_DB_ACTION_UPDATE_MANY.Before())
+}
+
+func tenantRecords(e *DBEvent) []interface{} {
+	if records := e.Records(); records != nil {
+		return records
+	}
+	return []interface{}{e.Result()}
+}
+
+// IsTenantScoped returns if resource records are isolated by tenant
+func (res *Resource) IsTenantScoped() bool {
+	return res.TenantScope != nil
+}
+
+// TenantDB returns db with tenant condition if resource is tenant scoped
+func (res *Resource) TenantDB(ctx *core.Context, db *aorm.DB) (_ *aorm.DB, err error) {
+	if res.TenantScope == nil {
+		return db, nil
+	}
+	var tenantID string
+	if tenantID, err = res.tenantID(ctx); err != nil || tenantID == "" {
+		return db, err
+	}
+	return res.TenantScope.Where(res, db, tenantID), nil
+}
+
+func (res *Resource) tenantID(ctx *core.Context) (tenantID string, err error) {
+	var ok bool
+	if tenantID, ok = ctx.Tenant(); !ok && ctx.Site == nil {
+		err = errwrap.Wrap(ErrTenantNotInContext, "Resource %q", res.UID)
+	}
+	return
+}
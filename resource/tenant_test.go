@@ -0,0 +1,185 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ecletus/core"
+	"github.com/go-aorm/aorm"
+)
+
+type tenantItem struct {
+	ID     int64  `aorm:"primary_key"`
+	SiteID string `aorm:"size:64"`
+	Name   string `aorm:"size:64"`
+}
+
+type tenantCode string
+
+func (this *tenantCode) UnmarshalText(text []byte) error {
+	*this = tenantCode(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestTenantScopeStamp(t *testing.T) {
+	type (
+		intTenant  struct{ SiteID int32 }
+		uintTenant struct{ SiteID uint8 }
+		ptrTenant  struct{ SiteID *string }
+		textTenant struct{ SiteID tenantCode }
+	)
+
+	cases := []struct {
+		record   interface{}
+		tenantID string
+		want     string
+		err      bool
+	}{
+		{&intTenant{}, "12", "12", false},
+		{&intTenant{}, "x", "", true},
+		{&uintTenant{}, "255", "255", false},
+		{&uintTenant{}, "256", "", true},
+		{&ptrTenant{}, "a", "a", false},
+		{&textTenant{}, "ab", "AB", false},
+	}
+
+	for i, c := range cases {
+		scope := &TenantScope{FieldName: "SiteID", Field: aorm.StructOf(c.record).FieldsByName["SiteID"]}
+		err := scope.Stamp(c.record, c.tenantID)
+		if c.err {
+			if err == nil {
+				t.Errorf("#%d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		field := reflect.Indirect(reflect.Indirect(reflect.ValueOf(c.record)).Field(0))
+		if got := fmt.Sprint(field.Interface()); got != c.want {
+			t.Errorf("#%d: expected %q, but got %q", i, c.want, got)
+		}
+	}
+}
+
+func TestTenantScopeCheck(t *testing.T) {
+	type ptrTenant struct{ SiteID *string }
+	a := "a"
+
+	cases := []struct {
+		record interface{}
+		want   string
+		err    error
+	}{
+		{&tenantItem{}, "b", nil},
+		{&tenantItem{SiteID: "b"}, "b", nil},
+		{&tenantItem{SiteID: "a"}, "a", aorm.ErrRecordNotFound},
+		{&ptrTenant{}, "b", nil},
+		{&ptrTenant{SiteID: &a}, "a", aorm.ErrRecordNotFound},
+	}
+
+	for i, c := range cases {
+		scope := &TenantScope{FieldName: "SiteID", Field: aorm.StructOf(c.record).FieldsByName["SiteID"]}
+		if err := scope.Check(c.record, "b"); err != c.err {
+			t.Errorf("#%d: expected error %v, but got %v", i, c.err, err)
+		}
+		field := reflect.Indirect(reflect.Indirect(reflect.ValueOf(c.record)).FieldByName("SiteID"))
+		if got := fmt.Sprint(field.Interface()); got != c.want {
+			t.Errorf("#%d: expected tenant %q, but got %q", i, c.want, got)
+		}
+	}
+}
+
+func TestTenantFieldType(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		want  bool
+	}{
+		{"", true},
+		{new(string), true},
+		{int64(0), true},
+		{uint(0), true},
+		{tenantCode(""), true},
+		{float64(0), false},
+		{[]string{}, false},
+	}
+	for i, c := range cases {
+		if got := isTenantFieldType(reflect.TypeOf(c.value)); got != c.want {
+			t.Errorf("#%d: expected %v, but got %v", i, c.want, got)
+		}
+	}
+}
+
+func tenantCrud(res *Resource, db *aorm.DB, tenantID string) *CRUD {
+	return res.Crud(testContext(db.Set(core.TENANT_KEY, tenantID)))
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db := testDB(t, &tenantItem{})
+	res := New(&tenantItem{}, "", "", nil)
+	if err := res.SetTenantScoped(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := tenantCrud(res, db, "a"), tenantCrud(res, db, "b")
+
+	single := &tenantItem{Name: "single"}
+	if err := a.CallCreate(single); err != nil {
+		t.Fatal(err)
+	}
+	if single.SiteID != "a" {
+		t.Errorf("single create: expected tenant %q, but got %q", "a", single.SiteID)
+	}
+
+	batch := []interface{}{&tenantItem{Name: "batch 1"}, &tenantItem{Name: "batch 2"}}
+	if err := a.CallCreateMany(batch...); err != nil {
+		t.Fatal(err)
+	}
+	for i, record := range batch {
+		if siteID := record.(*tenantItem).SiteID; siteID != "a" {
+			t.Errorf("batch create #%d: expected tenant %q, but got %q", i, "a", siteID)
+		}
+	}
+
+	var items []tenantItem
+	if err := b.FindMany(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("find many: tenant %q sees %d records of tenant %q", "b", len(items), "a")
+	}
+	if err := b.FindOne(&tenantItem{}, res.GetKey(single)); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("find one: expected not found error, but got %v", err)
+	}
+
+	single.Name = "single of b"
+	if err := b.CallUpdate(single); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("update: expected not found error, but got %v", err)
+	}
+	if single.SiteID != "a" {
+		t.Errorf("update: expected tenant %q kept, but got %q", "a", single.SiteID)
+	}
+	// a record without tenant is stamped, then not matched
+	if err := b.CallUpdate(&tenantItem{ID: single.ID, Name: "unloaded of b"}); !aorm.IsRecordNotFoundError(err) {
+		t.Errorf("update unloaded: expected not found error, but got %v", err)
+	}
+	batch[0].(*tenantItem).Name = "batch of b"
+	if err := b.CallUpdateMany(batch[0]); err == nil || !strings.Contains(err.Error(), aorm.ErrRecordNotFound.Error()) {
+		t.Errorf("batch update: expected not found error, but got %v", err)
+	}
+
+	if err := a.FindMany(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 records of tenant %q, but got %d", "a", len(items))
+	}
+	for _, item := range items {
+		if item.SiteID != "a" || strings.Contains(item.Name, "of b") {
+			t.Errorf("record %d updated by tenant %q: %+v", item.ID, "b", item)
+		}
+	}
+}
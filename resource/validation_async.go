@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecletus/core"
+	errwrap "github.com/moisespsena-go/error-wrap"
+)
+
+// DefaultAsyncValidatorTimeout is the deadline of async validators when neither validator nor context defines it
+var DefaultAsyncValidatorTimeout = 10 * time.Second
+
+// AsyncValidator is a slow validator, such as an external VAT number check or DNS lookup, that runs concurrently
+// with the other async validators. Validate must return when goCtx is done.
+type AsyncValidator struct {
+	Name    string
+	Timeout time.Duration
+	Meta    func(goCtx context.Context, record interface{}, value *MetaValue, ctx *core.Context) error
+	Record  func(goCtx context.Context, record interface{}, values *MetaValues, ctx *core.Context) error
+}
+
+// AsyncValidator adds async validators to meta. They run only for non blank values.
+func (this *Meta) AsyncValidator(timeout time.Duration, f ...func(goCtx context.Context, record interface{}, value *MetaValue, ctx *core.Context) error) *Meta {
+	for _, f := range f {
+		this.asyncValidators = append(this.asyncValidators, &AsyncValidator{Name: this.Name, Timeout: timeout, Meta: f})
+	}
+	return this
+}
+
+// AsyncValidators returns the async validators of meta
+func (this *Meta) AsyncValidators() []*AsyncValidator {
+	return this.asyncValidators
+}
+
+// MetaAsyncValidatorer is implemented by metas with async validators
+type MetaAsyncValidatorer interface {
+	AsyncValidators() []*AsyncValidator
+}
+
+// AsyncValidator adds async record validators to resource
+func (res *Resource) AsyncValidator(validator ...*AsyncValidator) {
+	res.AsyncValidators = append(res.AsyncValidators, validator...)
+}
+
+type asyncValidation struct {
+	validator *AsyncValidator
+	value     *MetaValue
+	done      chan error
+}
+
+func (this *asyncValidation) timeout(ctx *core.Context) time.Duration {
+	if this.validator.Timeout > 0 {
+		return this.validator.Timeout
+	}
+	if ctx.ValidatorTimeout > 0 {
+		return ctx.ValidatorTimeout
+	}
+	return DefaultAsyncValidatorTimeout
+}
+
+func (this *asyncValidation) start(goCtx context.Context, record interface{}, values *MetaValues, ctx *core.Context) {
+	this.done = make(chan error, 1)
+	go func() {
+		goCtx, cancel := context.WithTimeout(goCtx, this.timeout(ctx))
+		defer cancel()
+
+		result := make(chan error, 1)
+		go func() {
+			if this.value != nil {
+				result <- this.validator.Meta(goCtx, record, this.value, ctx)
+			} else {
+				result <- this.validator.Record(goCtx, record, values, ctx)
+			}
+		}()
+
+		select {
+		case err := <-result:
+			this.done <- err
+		case <-goCtx.Done():
+			this.done <- errwrap.Wrap(goCtx.Err(), "async validator %q", this.validator.Name)
+		}
+	}()
+}
+
+// validateAsync runs validations concurrently and calls onError with their errors in the order of validations.
+// Pending validations are cancelled when onError stops or the standard context of ctx is done; the cancellation is
+// reported to onError.
+func validateAsync(record interface{}, values *MetaValues, ctx *core.Context, validations []*asyncValidation, onError func(err error) (stop bool)) (stop bool) {
+	if len(validations) == 0 {
+		return
+	}
+
+	stdCtx := ctx.StdContext()
+	if err := stdCtx.Err(); err != nil {
+		onError(errwrap.Wrap(err, "async validation"))
+		return true
+	}
+
+	goCtx, cancel := context.WithCancel(stdCtx)
+	defer cancel()
+
+	for _, v := range validations {
+		v.start(goCtx, record, values, ctx)
+	}
+
+	for _, v := range validations {
+		select {
+		case err := <-v.done:
+			if err != nil && onError(err) {
+				return true
+			}
+		case <-stdCtx.Done():
+			onError(errwrap.Wrap(stdCtx.Err(), "async validation"))
+			return true
+		}
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecletus/core"
+)
+
+func asyncValidations(f ...func(goCtx context.Context) error) (validations []*asyncValidation) {
+	for _, f := range f {
+		f := f
+		validations = append(validations, &asyncValidation{validator: &AsyncValidator{
+			Name: "test",
+			Record: func(goCtx context.Context, record interface{}, values *MetaValues, ctx *core.Context) error {
+				return f(goCtx)
+			},
+		}})
+	}
+	return
+}
+
+func TestValidateAsync(t *testing.T) {
+	var (
+		invalid = errors.New("invalid")
+		block   = func(goCtx context.Context) error {
+			<-goCtx.Done()
+			return goCtx.Err()
+		}
+	)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name  string
+		ctx   func() (*core.Context, context.CancelFunc)
+		funcs []func(goCtx context.Context) error
+		stop  bool
+		errs  []string
+	}{
+		{
+			name: "sync errors do not stop",
+			ctx: func() (*core.Context, context.CancelFunc) {
+				ctx := core.NewContext()
+				ctx.AddError(errors.New("sync"))
+				return ctx, func() {}
+			},
+			funcs: []func(goCtx context.Context) error{
+				func(goCtx context.Context) error { return nil },
+				func(goCtx context.Context) error { return invalid },
+			},
+			errs: []string{invalid.Error()},
+		},
+		{
+			name: "cancelled before start",
+			ctx: func() (*core.Context, context.CancelFunc) {
+				return core.NewContext().WithContext(cancelled), func() {}
+			},
+			funcs: []func(goCtx context.Context) error{block},
+			stop:  true,
+			errs:  []string{context.Canceled.Error()},
+		},
+		{
+			name: "cancelled while waiting",
+			ctx: func() (*core.Context, context.CancelFunc) {
+				goCtx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return core.NewContext().WithContext(goCtx), cancel
+			},
+			funcs: []func(goCtx context.Context) error{block, block},
+			stop:  true,
+			errs:  []string{context.Canceled.Error()},
+		},
+	}
+
+	for _, c := range cases {
+		var (
+			ctx, cancel = c.ctx()
+			errs        []error
+			done        = make(chan bool, 1)
+		)
+		go func() {
+			done <- validateAsync(nil, nil, ctx, asyncValidations(c.funcs...), func(err error) bool {
+				errs = append(errs, err)
+				return false
+			})
+		}()
+		select {
+		case stop := <-done:
+			if stop != c.stop {
+				t.Errorf("%s: expected stop %v, but got %v", c.name, c.stop, stop)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: validation not finished", c.name)
+			cancel()
+			continue
+		}
+		cancel()
+		if len(errs) != len(c.errs) {
+			t.Errorf("%s: expected errors %v, but got %v", c.name, c.errs, errs)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), c.errs[i]) {
+				t.Errorf("%s: expected error %q, but got %q", c.name, c.errs[i], err)
+			}
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package resource
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecletus/core"
+	"github.com/ecletus/core/utils"
+	"github.com/ecletus/validations"
+	"github.com/go-aorm/aorm"
+	"github.com/moisespsena-go/i18n-modular/i18nmod"
+	path_helpers "github.com/moisespsena-go/path-helpers"
+)
+
+// ValidationTag is the struct tag of field validation rules
+const ValidationTag = "validate"
+
+var (
+	i18ng = i18nmod.PkgToGroup(path_helpers.GetCalledDir())
+
+	emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+	validationRuleMessages = map[string]string{
+		"min_len":   "{{.Label}} must have at least {{.Arg}} characters",
+		"max_len":   "{{.Label}} must have at most {{.Arg}} characters",
+		"min":       "{{.Label}} must be greater than or equal to {{.Arg}}",
+		"max":       "{{.Label}} must be less than or equal to {{.Arg}}",
+		"regex":     "{{.Label}} has an invalid format",
+		"email":     "{{.Label}} must be a valid email address",
+		"url":       "{{.Label}} must be a valid URL",
+		"unique":    "{{.Label}} is already taken",
+		"eq_field":  "{{.Label}} must be equal to {{.ArgLabel}}",
+		"ne_field":  "{{.Label}} must be different from {{.ArgLabel}}",
+		"gt_field":  "{{.Label}} must be greater than {{.ArgLabel}}",
+		"gte_field": "{{.Label}} must be greater than or equal to {{.ArgLabel}}",
+		"lt_field":  "{{.Label}} must be less than {{.ArgLabel}}",
+		"lte_field": "{{.Label}} must be less than or equal to {{.ArgLabel}}",
+	}
+)
+
+// ValidationRule is a declarative meta validation rule. The rules are:
+//
+//	min_len=N, max_len=N        string length
+//	min=N, max=N                numeric range
+//	regex=EXPR                  regular expression, must be the last rule of tag
+//	email, url                  formats
+//	unique                      no other record has the same value, by DB lookup
+//	eq_field=F, ne_field=F, gt_field=F, gte_field=F, lt_field=F, lte_field=F
+//	                            comparison with value of meta F
+type ValidationRule struct {
+	Name string
+	Arg  string
+
+	num float64
+	re  *regexp.Regexp
+}
+
+// ParseValidationRules parses comma separated rules, such as `min_len=3,max_len=50,email`
+func ParseValidationRules(tag string) (rules []*ValidationRule, err error) {
+	for tag = strings.TrimSpace(tag); tag != ""; {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], strings.TrimSpace(tag[i+1:])
+		} else {
+			part, tag = tag, ""
+		}
+
+		rule := &ValidationRule{Name: strings.TrimSpace(part)}
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			rule.Name, rule.Arg = strings.TrimSpace(part[:i]), part[i+1:]
+		}
+		if _, ok := validationRuleMessages[rule.Name]; !ok {
+			return nil, fmt.Errorf("resource: unknown validation rule %q", rule.Name)
+		}
+		switch rule.Name {
+		case "min_len", "max_len", "min", "max":
+			if rule.num, err = strconv.ParseFloat(rule.Arg, 64); err != nil {
+				return nil, fmt.Errorf("resource: validation rule %q: %v", rule.Name, err)
+			}
+		case "regex":
+			if rule.re, err = regexp.Compile(rule.Arg); err != nil {
+				return nil, fmt.Errorf("resource: validation rule %q: %v", rule.Name, err)
+			}
+		case "eq_field", "ne_field", "gt_field", "gte_field", "lt_field", "lte_field":
+			if rule.Arg == "" {
+				return nil, fmt.Errorf("resource: validation rule %q: field name is blank", rule.Name)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// ValidationRuleError is the error of validation rule, translated by `<core group>.errors.rules.<rule name>`
+type ValidationRuleError struct {
+	Rule     *ValidationRule
+	Label    string
+	Arg      string
+	ArgLabel string
+}
+
+func (this *ValidationRuleError) Error() string {
+	return fmt.Sprintf("%s: validation rule %q failed", this.Label, this.Rule.Name)
+}
+
+func (this *ValidationRuleError) Translate(ctx i18nmod.Context) string {
+	return ctx.TT(i18ng + ".errors.rules." + this.Rule.Name).DefaultArgs(validationRuleMessages[this.Rule.Name]).Data(this).Get()
+}
+
+// MetaValidationRuler is implemented by metas with validation rules
+type MetaValidationRuler interface {
+	GetValidationRules() []*ValidationRule
+}
+
+// Rule adds the validation rules of tag to meta. Panics if tag is invalid.
+func (this *Meta) Rule(tag string) *Meta {
+	rules, err := ParseValidationRules(tag)
+	if err != nil {
+		panic(fmt.Errorf("meta %q: %v", this.Name, err))
+	}
+	this.validationRules = append(this.validationRules, rules...)
+	return this
+}
+
+// GetValidationRules returns the validation rules of meta
+func (this *Meta) GetValidationRules() []*ValidationRule {
+	return this.validationRules
+}
+
+// ValidateRules checks the value of meta value by validation rules. The fields compared by rules are looked up in
+// values, the meta values of record, or in record fields if absent.
+func ValidateRules(ctx *core.Context, res Resourcer, record interface{}, values *MetaValues, value *MetaValue, rules []*ValidationRule) (errors []error) {
+	var (
+		meta  = value.Meta
+		str   = utils.ToString(value.Value)
+		label = meta.GetRecordLabelC(ctx, record)
+	)
+	for _, rule := range rules {
+		var (
+			ok       = true
+			argLabel string
+			err      error
+		)
+		switch rule.Name {
+		case "min_len":
+			ok = float64(len([]rune(str))) >= rule.num
+		case "max_len":
+			ok = float64(len([]rune(str))) <= rule.num
+		case "min", "max":
+			var v float64
+			if v, err = strconv.ParseFloat(strings.TrimSpace(str), 64); err != nil {
+				ok = false
+			} else if rule.Name == "min" {
+				ok = v >= rule.num
+			} else {
+				ok = v <= rule.num
+			}
+		case "regex":
+			ok = rule.re.MatchString(str)
+		case "email":
+			ok = emailRe.MatchString(str)
+		case "url":
+			u, err := url.Parse(str)
+			ok = err == nil && u.Scheme != "" && u.Host != ""
+		case "unique":
+			if ok, err = validateUnique(ctx, res, record, meta, value.Value); err != nil {
+				errors = append(errors, err)
+				continue
+			}
+		default:
+			var other interface{}
+			if other, argLabel, ok = validationRuleField(ctx, res, record, values, rule.Arg); !ok {
+				continue
+			}
+			c := compareValues(str, utils.ToString(other))
+			switch rule.Name {
+			case "eq_field":
+				ok = c == 0
+			case "ne_field":
+				ok = c != 0
+			case "gt_field":
+				ok = c > 0
+			case "gte_field":
+				ok = c >= 0
+			case "lt_field":
+				ok = c < 0
+			case "lte_field":
+				ok = c <= 0
+			}
+		}
+		if !ok {
+			ruleErr := &ValidationRuleError{Rule: rule, Label: label, Arg: rule.Arg, ArgLabel: argLabel}
+			var msg string
+			if ctx.Translator != nil || ctx.I18nContext != nil {
+				msg = ctx.ErrorTS(ruleErr)
+			} else {
+				msg = strings.NewReplacer("{{.Label}}", label, "{{.Arg}}", rule.Arg, "{{.ArgLabel}}", argLabel).
+					Replace(validationRuleMessages[rule.Name])
+			}
+			errors = append(errors, validations.NewError(record, meta.GetName(), msg))
+		}
+	}
+	return
+}
+
+// validationRuleField returns the value and label of field name from values, or from record if absent
+func validationRuleField(ctx *core.Context, res Resourcer, record interface{}, values *MetaValues, name string) (value interface{}, label string, ok bool) {
+	if values != nil {
+		if other := values.Get(name); other != nil {
+			if other.Meta != nil {
+				return other.Value, other.Meta.GetRecordLabelC(ctx, record), true
+			}
+			return other.Value, utils.HumanizeString(name), true
+		}
+	}
+	if field, ok := res.GetModelStruct().FieldsByName[name]; ok {
+		if v := reflect.Indirect(reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(field.StructIndex)); v.IsValid() {
+			if t, isTime := v.Interface().(time.Time); isTime {
+				return t.Format(time.RFC3339Nano), utils.HumanizeString(name), true
+			}
+			return v.Interface(), utils.HumanizeString(name), true
+		}
+	}
+	return
+}
+
+// compareValues compares a and b as numbers, times or strings
+func compareValues(a, b string) int {
+	if x, err := strconv.ParseFloat(strings.TrimSpace(a), 64); err == nil {
+		if y, err := strconv.ParseFloat(strings.TrimSpace(b), 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if x, err := time.Parse(layout, a); err == nil {
+			if y, err := time.Parse(layout, b); err == nil {
+				switch {
+				case x.Before(y):
+					return -1
+				case x.After(y):
+					return 1
+				}
+				return 0
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func validateUnique(ctx *core.Context, res Resourcer, record interface{}, meta Metaor, value interface{}) (ok bool, err error) {
+	field := meta.GetFieldStruct()
+	if field == nil {
+		return true, nil
+	}
+	if !isAormRepository(res.GetResource().GetRepository()) {
+		return validateUniqueRecords(ctx, res, record, field, value)
+	}
+	var (
+		DB    = ctx.DB().New()
+		scope = DB.NewScope(res.GetValue())
+		count int
+	)
+	if DB, err = res.GetResource().TenantDB(ctx, DB); err != nil {
+		return
+	}
+	DB = DB.Model(res.GetValue()).Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)), value)
+	if id := res.GetKey(record); id != nil && !id.IsZero() {
+		var (
+			query string
+			args  []interface{}
+		)
+		if query, args, err = IdToPrimaryQuery(ctx, res, true, id); err != nil {
+			return
+		}
+		DB = DB.Where(query, args...)
+	}
+	if err = DB.Count(&count).Error; err != nil {
+		return
+	}
+	return count == 0, nil
+}
+
+// validateUniqueRecords checks the unique value comparing it with the field of all records found by repository
+func validateUniqueRecords(ctx *core.Context, res Resourcer, record interface{}, field *aorm.StructField, value interface{}) (ok bool, err error) {
+	slice := res.NewSlicePtr()
+	if err = NewCrud(res, ctx).FindMany(slice); err != nil {
+		return
+	}
+	var (
+		id       = res.GetKey(record)
+		hasID    = id != nil && !id.IsZero()
+		expected = fmt.Sprint(value)
+		records  = reflect.Indirect(reflect.ValueOf(slice))
+	)
+	for i := 0; i < records.Len(); i++ {
+		other := records.Index(i).Interface()
+		if hasID && res.GetKey(other).String() == id.String() {
+			continue
+		}
+		v := reflect.Indirect(reflect.Indirect(reflect.ValueOf(other)).FieldByIndex(field.StructIndex))
+		if v.IsValid() && fmt.Sprint(v.Interface()) == expected {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidationRulesUIAttrs returns the HTML input attributes of rules
+func ValidationRulesUIAttrs(rules []*ValidationRule) string {
+	var attrs []string
+	for _, rule := range rules {
+		switch rule.Name {
+		case "min_len":
+			attrs = append(attrs, `minlength="`+rule.Arg+`"`)
+		case "max_len":
+			attrs = append(attrs, `maxlength="`+rule.Arg+`"`)
+		case "min", "max":
+			attrs = append(attrs, rule.Name+`="`+rule.Arg+`"`)
+		case "regex":
+			attrs = append(attrs, `pattern="`+html.EscapeString(rule.Arg)+`"`)
+		case "email", "url":
+			attrs = append(attrs, `data-format="`+rule.Name+`"`)
+		}
+	}
+	return strings.Join(attrs, " ")
+}
@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ecletus/core"
+)
+
+func TestParseValidationRules(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want [][2]string
+		err  bool
+	}{
+		{"", nil, false},
+		{"min_len=3, max_len=50,email", [][2]string{{"min_len", "3"}, {"max_len", "50"}, {"email", ""}}, false},
+		{"min=1,max=9.5,url,unique", [][2]string{{"min", "1"}, {"max", "9.5"}, {"url", ""}, {"unique", ""}}, false},
+		{"eq_field=Password", [][2]string{{"eq_field", "Password"}}, false},
+		{"min_len=1,regex=^a,b{1,2}$", [][2]string{{"min_len", "1"}, {"regex", "^a,b{1,2}$"}}, false},
+		{"unknown", nil, true},
+		{"min=x", nil, true},
+		{"max_len=", nil, true},
+		{"regex=(", nil, true},
+		{"gt_field=", nil, true},
+	}
+	for _, tt := range tests {
+		rules, err := ParseValidationRules(tt.tag)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tt.tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.tag, err)
+			continue
+		}
+		var got [][2]string
+		for _, rule := range rules {
+			got = append(got, [2]string{rule.Name, rule.Arg})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, but got %v", tt.tag, tt.want, got)
+		}
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	var (
+		res  = memoryResource(t, &tenantItem{ID: 1, Name: "a"})
+		meta = &Meta{MetaName: &MetaName{Name: "Name"}, FieldName: "Name", FieldStruct: res.ModelStruct.FieldsByName["Name"]}
+	)
+	tests := []struct {
+		tag     string
+		value   string
+		sibling interface{} // the SiteID meta value, or nil to compare with record field
+		site    string      // the SiteID record field
+		valid   bool
+	}{
+		{"min_len=2", "ab", nil, "", true},
+		{"min_len=2", "á", nil, "", false},
+		{"max_len=2", "ab", nil, "", true},
+		{"max_len=2", "abc", nil, "", false},
+		{"min=2", "2", nil, "", true},
+		{"min=2", "1.5", nil, "", false},
+		{"min=2", "x", nil, "", false},
+		{"max=2", "2", nil, "", true},
+		{"max=2", "3", nil, "", false},
+		{"regex=^a,b$", "a,b", nil, "", true},
+		{"regex=^a,b$", "ab", nil, "", false},
+		{"email", "a@b.c", nil, "", true},
+		{"email", "a@b", nil, "", false},
+		{"url", "http://x.y/z", nil, "", true},
+		{"url", "x.y/z", nil, "", false},
+		{"unique", "b", nil, "", true},
+		{"unique", "a", nil, "", false},
+		{"eq_field=SiteID", "a", "a", "", true},
+		{"eq_field=SiteID", "a", "b", "", false},
+		{"eq_field=SiteID", "a", nil, "a", true},
+		{"eq_field=SiteID", "a", nil, "b", false},
+		{"eq_field=Unknown", "a", nil, "", true},
+		{"ne_field=SiteID", "a", "b", "", true},
+		{"ne_field=SiteID", "a", "a", "", false},
+		{"gt_field=SiteID", "10", "9", "", true},
+		{"gt_field=SiteID", "9", "10", "", false},
+		{"gte_field=SiteID", "2", "2", "", true},
+		{"gte_field=SiteID", "2020-01-01", "2020-01-02", "", false},
+		{"lt_field=SiteID", "2020-01-01", "2020-01-02", "", true},
+		{"lt_field=SiteID", "b", "a", "", false},
+		{"lte_field=SiteID", "a", "a", "", true},
+		{"lte_field=SiteID", "b", "a", "", false},
+	}
+	for i, tt := range tests {
+		rules, err := ParseValidationRules(tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			record = &tenantItem{Name: tt.value, SiteID: tt.site}
+			value  = &MetaValue{Name: "Name", Meta: meta, Value: tt.value}
+			values = &MetaValues{}
+		)
+		values.Add(value)
+		if tt.sibling != nil {
+			values.Add(&MetaValue{Name: "SiteID", Value: tt.sibling})
+		}
+		errs := ValidateRules(core.NewContext(), res, record, values, value, rules)
+		if valid := len(errs) == 0; valid != tt.valid {
+			t.Errorf("#%d: %q of %q: expected valid %v, but got errors %v", i, tt.tag, tt.value, tt.valid, errs)
+		}
+	}
+}
@@ -347,6 +347,10 @@ func (this *Site) PrepareContext(ctx *Context) *Context {
 		ctx.LangTag = &tag
 	}
 
+	if this.RowTenancy() {
+		DB = DB.Set(TENANT_KEY, this.TenantID())
+	}
+
 	ctx.Role = this.role.Copy()
 	ctx.SetRequestTime(time.Now())
 	ctx.SetDB(DB.Set(CONTEXT_KEY, ctx))
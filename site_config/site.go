@@ -13,6 +13,13 @@ import (
 	"github.com/moisespsena-go/stringvar"
 )
 
+const (
+	// TenantIsolationDatabase each site uses its own databases
+	TenantIsolationDatabase = "database"
+	// TenantIsolationRow sites share the database and tenant scoped records are isolated by the site ID column
+	TenantIsolationRow = "row"
+)
+
 type Config struct {
 	Title        string
 	Db           map[string]*dbconfig.DBConfig
@@ -25,7 +32,10 @@ type Config struct {
 	Locale       string                            `mapstructure:"locale"`
 	Lang         string                            `mapstructure:"lang"`
 	TimeLocation string                            `mapstructure:"time_location"`
-	Raw          maps.MapSI
+	// TenantIsolation is the tenant isolation mode. Accepts TenantIsolationDatabase (default) or
+	// TenantIsolationRow.
+	TenantIsolation string `mapstructure:"tenant_isolation"`
+	Raw             maps.MapSI
 }
 
 func (this *Config) Prepare(mainDBConfig map[string]*dbconfig.DBConfig, siteName string, args *stringvar.StringVar) (err error) {
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"github.com/ecletus/core/site_config"
+	"github.com/go-aorm/aorm"
+)
+
+// TENANT_KEY is the DB setting key of the current tenant ID on row isolated sites
+var TENANT_KEY = PREFIX + ".tenant"
+
+// RowTenancy returns if site shares the database with other sites and uses row-level isolation
+func (this *Site) RowTenancy() bool {
+	return this.basicConfig.TenantIsolation == site_config.TenantIsolationRow
+}
+
+// TenantID returns the tenant ID of site. The tenant ID is the site name.
+func (this *Site) TenantID() string {
+	return this.name
+}
+
+// GetTenantFromDB returns the tenant ID set on DB by Site.PrepareContext
+func GetTenantFromDB(db *aorm.DB) (tenantID string, ok bool) {
+	var v interface{}
+	if v, ok = db.Get(TENANT_KEY); ok {
+		tenantID, ok = v.(string)
+	}
+	return
+}
+
+// Tenant returns the current tenant ID if context site uses row-level isolation
+func (this *Context) Tenant() (tenantID string, ok bool) {
+	if this.db != nil {
+		if tenantID, ok = GetTenantFromDB(this.db); ok {
+			return
+		}
+	}
+	if this.Site != nil && this.Site.RowTenancy() {
+		return this.Site.TenantID(), true
+	}
+	return
+}